@@ -0,0 +1,23 @@
+data "xray_license_group" "copyleft" {
+  categories = ["strong_copyleft", "network_copyleft"]
+}
+
+resource "xray_license_policy" "no_copyleft" {
+  name = "no-copyleft"
+  type = "license"
+
+  rule {
+    name = "copyleft"
+    criteria {
+      banned_licenses = data.xray_license_group.copyleft.licenses
+      allow_unknown   = true
+    }
+    actions {
+      fail_build = true
+      block_download {
+        unscanned = false
+        active    = false
+      }
+    }
+  }
+}
@@ -0,0 +1,23 @@
+data "xray_policies" "team_security" {
+  type       = "security"
+  name_regex = "^team-a-"
+}
+
+resource "xray_watch" "team_security" {
+  for_each = toset(data.xray_policies.team_security.names)
+
+  name        = "${each.value}-watch"
+  description = "Watch for the ${each.value} policy"
+  active      = true
+
+  watch_resource {
+    type = "all-repos"
+  }
+
+  assigned_policy {
+    name = each.value
+    type = "security"
+  }
+
+  watch_recipients = ["test@email.com"]
+}
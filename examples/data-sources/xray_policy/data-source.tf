@@ -0,0 +1,22 @@
+data "xray_policy" "security" {
+  name        = "central-security-policy"
+  project_key = "testproj"
+}
+
+resource "xray_watch" "team_watch" {
+  name        = "team-watch"
+  description = "Watch using a centrally-managed policy"
+  active      = true
+  project_key = "testproj"
+
+  watch_resource {
+    type = "all-repos"
+  }
+
+  assigned_policy {
+    name = data.xray_policy.security.name
+    type = data.xray_policy.security.type
+  }
+
+  watch_recipients = ["test@email.com"]
+}
@@ -0,0 +1,11 @@
+data "xray_policy_assignments" "security" {
+  policy_name = "security-policy"
+}
+
+output "security_policy_watches" {
+  value = data.xray_policy_assignments.security.watch_names
+}
+
+output "security_policy_recipients" {
+  value = distinct(flatten(data.xray_policy_assignments.security.watches[*].watch_recipients))
+}
@@ -0,0 +1,29 @@
+data "xray_policy_document" "security" {
+  name        = "security-policy"
+  description = "Security policy reviewed as JSON"
+  type        = "security"
+
+  rule {
+    name = "high-severity"
+    criteria {
+      min_severity = "High"
+    }
+    actions {
+      fail_build = true
+      block_download {
+        unscanned = true
+        active    = true
+      }
+    }
+  }
+}
+
+output "security_policy_json" {
+  value = data.xray_policy_document.security.json
+}
+
+resource "xray_policy" "security" {
+  name       = "security-policy"
+  type       = "security"
+  rules_json = data.xray_policy_document.security.rules_json
+}
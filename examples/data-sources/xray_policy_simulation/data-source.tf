@@ -0,0 +1,55 @@
+# Evaluate the rules of an existing policy
+data "xray_policy_simulation" "licenses" {
+  policy_name = "license-policy"
+
+  finding {
+    component = "gav://org.example:library:1.0.0"
+    licenses  = ["MIT", "GPL-3.0"]
+  }
+
+  finding {
+    component = "npm://unknown-license:2.1.0"
+  }
+}
+
+# Evaluate rules before they're applied
+data "xray_policy_simulation" "security" {
+  type = "security"
+
+  rule {
+    name     = "critical"
+    priority = 1
+    criteria {
+      min_severity          = "Critical"
+      fix_version_dependant = true
+    }
+    actions {
+      fail_build = true
+      block_download {
+        unscanned = false
+        active    = true
+      }
+    }
+  }
+
+  rule {
+    name     = "medium-cvss"
+    priority = 2
+    criteria {
+      cvss_range {
+        from = 4.0
+        to   = 6.9
+      }
+    }
+  }
+
+  finding {
+    component             = "npm://lodash:4.17.20"
+    cvss_score            = 9.1
+    fix_version_available = true
+  }
+}
+
+output "license_violations" {
+  value = [for r in data.xray_policy_simulation.licenses.result : r.component if r.violation]
+}
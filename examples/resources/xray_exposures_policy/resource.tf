@@ -0,0 +1,37 @@
+resource "xray_exposures_policy" "secrets" {
+  name        = "test-exposures-policy-secrets"
+  description = "Exposures policy for exposed secrets and IaC misconfigurations"
+  type        = "exposures"
+  project_key = "testproj"
+
+  rule {
+    name     = "exposures_rule"
+    priority = 1
+
+    criteria {
+      exposures {
+        min_severity = "High"
+        secrets      = true
+        applications = false
+        services     = false
+        iac          = true
+      }
+    }
+
+    actions {
+      webhooks                           = []
+      mails                              = ["test@email.com"]
+      block_release_bundle_distribution  = false
+      fail_build                         = true
+      notify_watch_recipients            = true
+      notify_deployer                    = true
+      create_ticket_enabled              = false // set to true only if Jira integration is enabled
+      build_failure_grace_period_in_days = 5 // use only if fail_build is enabled
+
+      block_download {
+        unscanned = true
+        active    = true
+      }
+    }
+  }
+}
@@ -45,7 +45,7 @@ resource "xray_license_policy" "banned_licenses" {
     priority = 1
 
     criteria {
-      banned_licenses          = ["Apache-3.0", "Apache-4.0"]
+      banned_licenses          = ["GPL-3.0-only", "AGPL-3.0-only"]
       allow_unknown            = false
       multi_license_permissive = false
     }
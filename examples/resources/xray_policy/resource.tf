@@ -0,0 +1,32 @@
+resource "xray_policy" "license" {
+  name        = "test-generic-license-policy"
+  description = "License policy with the rules in the JSON format of the Xray API"
+  type        = "license"
+  project_key = "testproj"
+
+  rules_json = jsonencode([
+    {
+      name     = "banned-licenses"
+      priority = 1
+      criteria = {
+        banned_licenses = ["GPL-3.0", "AGPL-3.0"]
+        allow_unknown   = false
+      }
+      actions = {
+        fail_build      = true
+        custom_severity = "High"
+        block_download = {
+          unscanned = false
+          active    = true
+        }
+      }
+    }
+  ])
+}
+
+# The rules can also be exported from Xray
+resource "xray_policy" "exported" {
+  name       = "test-exported-policy"
+  type       = "security"
+  rules_json = file("${path.module}/exported-rules.json")
+}
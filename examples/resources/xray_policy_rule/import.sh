@@ -0,0 +1,3 @@
+# The ID is the name of the policy and the name of the rule, followed by the project key for the policies of a project
+terraform import xray_policy_rule.team_a security-baseline:team-a-log4j
+terraform import xray_policy_rule.team_b security-baseline:team-b-rule:myproj
@@ -0,0 +1,44 @@
+# The baseline rules of the policy are owned by the central security team
+resource "xray_security_policy" "baseline" {
+  name                  = "security-baseline"
+  description           = "Security baseline, extended by the product teams"
+  type                  = "security"
+  ignore_external_rules = true
+
+  rule {
+    name = "critical"
+
+    criteria {
+      min_severity = "Critical"
+    }
+
+    actions {
+      fail_build = true
+
+      block_download {
+        unscanned = false
+        active    = true
+      }
+    }
+  }
+}
+
+# A product team adds its own stricter rule to the policy
+resource "xray_policy_rule" "team_a" {
+  policy_name = xray_security_policy.baseline.name
+  name        = "team-a-log4j"
+
+  criteria {
+    vulnerability_ids = ["CVE-2021-44228", "CVE-2021-45046"]
+  }
+
+  actions {
+    mails      = ["team-a@email.com"]
+    fail_build = true
+
+    block_download {
+      unscanned = false
+      active    = true
+    }
+  }
+}
@@ -0,0 +1,5 @@
+# The ID is the name of the set and the names of its policies separated by commas, followed by the name of the watch
+# and the project key when the set has a watch or belongs to a project
+terraform import xray_policy_set.release release-gates:release-security,release-license
+terraform import xray_policy_set.release release-gates:release-security,release-license:release-watch
+terraform import xray_policy_set.team team:team-security::myproj
@@ -0,0 +1,54 @@
+resource "xray_policy_set" "release" {
+  name        = "release-gates"
+  project_key = "testproj"
+
+  policy {
+    name = "release-security"
+    type = "security"
+    rules_json = jsonencode([
+      {
+        name     = "high-severity"
+        priority = 1
+        criteria = { min_severity = "High" }
+        actions = {
+          fail_build = true
+          block_download = {
+            unscanned = false
+            active    = true
+          }
+        }
+      }
+    ])
+  }
+
+  policy {
+    name = "release-license"
+    type = "license"
+    rules_json = jsonencode([
+      {
+        name     = "banned-licenses"
+        priority = 1
+        criteria = {
+          banned_licenses = ["GPL-3.0", "AGPL-3.0"]
+          allow_unknown   = false
+        }
+        actions = {
+          fail_build = true
+        }
+      }
+    ])
+  }
+
+  # All the policies of the set are assigned to the watch
+  watch {
+    name   = "release-watch"
+    active = true
+
+    watch_resource {
+      type = "repository"
+      name = "release-local"
+    }
+
+    watch_recipients = ["security@example.com"]
+  }
+}
@@ -1,3 +1,4 @@
 resource "xray_settings" "db_sync" {
   db_sync_updates_time = "18:40"
+  restore_on_destroy   = true
 }
\ No newline at end of file
@@ -27,10 +27,11 @@ resource "xray_watch" "all-repos" {
 }
 
 resource "xray_watch" "repository" {
-  name        = "repository-watch"
-  description = "Watch a single repo or a list of repositories"
-  active      = true
-  project_key = "testproj"
+  name                    = "repository-watch"
+  description             = "Watch a single repo or a list of repositories"
+  active                  = true
+  project_key             = "testproj"
+  check_watched_resources = "warning"
 
   watch_resource {
     type       = "repository"
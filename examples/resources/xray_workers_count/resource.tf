@@ -1,4 +1,6 @@
 resource "xray_workers_count" "workers-count" {
+  restore_on_destroy = true
+
   index {
     new_content      = 4
     existing_content = 2
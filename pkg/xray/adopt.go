@@ -0,0 +1,171 @@
+package xray
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var adoptExistingSchema = map[string]*schema.Schema{
+	"adopt_existing": {
+		Type:     schema.TypeBool,
+		Optional: true,
+		Description: "Default value is `false`. If an object with the same name already exists in Xray, e.g. after a failed apply or a manual creation, " +
+			"take it under management and update it to match the configuration. Otherwise, creating the resource fails with the ID to import the object.",
+	},
+}
+
+// objectExists checks if the Xray object at the path exists, e.g. to find out why a create request failed
+func objectExists(client *resty.Client, projectKey, path string, pathParams map[string]string) (bool, error) {
+	req, err := getRestyRequest(client, projectKey)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := req.
+		SetPathParams(pathParams).
+		Get(path)
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// alreadyExistsDiagnostics is the error for an object which exists in Xray but isn't managed by Terraform
+func alreadyExistsDiagnostics(resourceType, objectName, importId string) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s already exists", objectName),
+		Detail: fmt.Sprintf("%s already exists in Xray and isn't managed by this resource. "+
+			"Import it with `terraform import %s.<name> %s`, or set `adopt_existing = true` to take it under management.",
+			objectName, resourceType, importId),
+	}}
+}
+
+// The singleton settings, such as the DB sync time and the workers counts, always exist in Xray, so their
+// resources adopt them on create. The current value is recorded in an `original_*` attribute before it's
+// updated (or on the first read after an import), and it's restored on destroy if `restore_on_destroy` is
+// set. Otherwise, destroying the resource leaves the setting unchanged.
+
+// recordOriginalSetting records the value of the setting in the originalAttr attribute, unless it's already recorded
+func recordOriginalSetting(d *schema.ResourceData, originalAttr, value string) error {
+	if len(d.Get(originalAttr).(string)) > 0 {
+		return nil
+	}
+
+	return d.Set(originalAttr, value)
+}
+
+// originalSettingToRestore returns the original value of the setting to restore on destroy, and false when
+// restore_on_destroy isn't set or the original value wasn't recorded
+func originalSettingToRestore(d *schema.ResourceData, originalAttr string) (string, bool) {
+	original := d.Get(originalAttr).(string)
+	return original, d.Get("restore_on_destroy").(bool) && len(original) > 0
+}
+
+type IgnoreRules struct {
+	Data       []IgnoreRule `json:"data"`
+	TotalCount int          `json:"total_count"`
+}
+
+// findIgnoreRule returns the ID of the ignore rule, which isn't expired, with the same notes, expiration date
+// and filters as ignoreRule. Ignore rules have no name, so they are identified by their content.
+func findIgnoreRule(client *resty.Client, ignoreRule IgnoreRule) (string, bool, error) {
+	const pageSize = 100
+
+	for page := 1; ; page++ {
+		ignoreRules := IgnoreRules{}
+
+		req, err := getRestyRequest(client, ignoreRule.ProjectKey)
+		if err != nil {
+			return "", false, err
+		}
+
+		_, err = req.
+			SetQueryParams(map[string]string{
+				"page_num":    fmt.Sprint(page),
+				"num_of_rows": fmt.Sprint(pageSize),
+			}).
+			SetResult(&ignoreRules).
+			Get("xray/api/v1/ignore_rules")
+		if err != nil {
+			return "", false, err
+		}
+
+		for _, existing := range ignoreRules.Data {
+			if !existing.IsExpired && sameIgnoreRule(existing, ignoreRule) {
+				return existing.Id, true, nil
+			}
+		}
+
+		if len(ignoreRules.Data) < pageSize || page*pageSize >= ignoreRules.TotalCount {
+			return "", false, nil
+		}
+	}
+}
+
+func sameIgnoreRule(a, b IgnoreRule) bool {
+	formatDate := func(t IgnoreRule) string {
+		if t.ExpiresAt == nil {
+			return ""
+		}
+		return t.ExpiresAt.Format("2006-01-02")
+	}
+
+	return a.Notes == b.Notes &&
+		formatDate(a) == formatDate(b) &&
+		reflect.DeepEqual(sortIgnoreFilters(a.IgnoreFilters), sortIgnoreFilters(b.IgnoreFilters))
+}
+
+// sortIgnoreFilters sorts the filters, which are sets, to compare them
+func sortIgnoreFilters(filters IgnoreFilters) IgnoreFilters {
+	sortStrings := func(values []string) []string {
+		if len(values) == 0 {
+			return nil
+		}
+		sorted := append([]string{}, values...)
+		sort.Strings(sorted)
+		return sorted
+	}
+	sortNameVersions := func(values []IgnoreFilterNameVersion) []IgnoreFilterNameVersion {
+		if len(values) == 0 {
+			return nil
+		}
+		sorted := append([]IgnoreFilterNameVersion{}, values...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Name+":"+sorted[i].Version < sorted[j].Name+":"+sorted[j].Version
+		})
+		return sorted
+	}
+
+	sorted := IgnoreFilters{
+		Vulnerabilities:  sortStrings(filters.Vulnerabilities),
+		Licenese:         sortStrings(filters.Licenese),
+		CVEs:             sortStrings(filters.CVEs),
+		Policies:         sortStrings(filters.Policies),
+		Watches:          sortStrings(filters.Watches),
+		DockerLayers:     sortStrings(filters.DockerLayers),
+		OperationalRisks: sortStrings(filters.OperationalRisks),
+		ReleaseBundles:   sortNameVersions(filters.ReleaseBundles),
+		Builds:           sortNameVersions(filters.Builds),
+		Components:       sortNameVersions(filters.Components),
+	}
+	if len(filters.Artifacts) > 0 {
+		sorted.Artifacts = append([]IgnoreFilterNameVersionPath{}, filters.Artifacts...)
+		sort.Slice(sorted.Artifacts, func(i, j int) bool {
+			a, b := sorted.Artifacts[i], sorted.Artifacts[j]
+			return a.Name+":"+a.Version+":"+a.Path < b.Name+":"+b.Version+":"+b.Path
+		})
+	}
+
+	return sorted
+}
@@ -0,0 +1,168 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testAdoptWatchData(t *testing.T, description string, adoptExisting bool) *schema.ResourceData {
+	return schema.TestResourceDataRaw(t, resourceXrayWatch().Schema, map[string]interface{}{
+		"name":           "test-watch",
+		"description":    description,
+		"active":         true,
+		"adopt_existing": adoptExisting,
+		"watch_resource": []interface{}{
+			map[string]interface{}{"type": "all-repos"},
+		},
+		"assigned_policy": []interface{}{
+			map[string]interface{}{"name": "test-policy", "type": "security"},
+		},
+	})
+}
+
+func TestWatchCreateAdoptExisting(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+
+	if diags := resourceXrayWatchCreate(context.Background(), testAdoptWatchData(t, "manual", false), restyClient); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	diags := resourceXrayWatchCreate(context.Background(), testAdoptWatchData(t, "managed", false), restyClient)
+	if !diags.HasError() || diags[0].Summary != "Watch test-watch already exists" ||
+		!strings.Contains(diags[0].Detail, "terraform import xray_watch.<name> test-watch") {
+		t.Fatalf("expected a conflict error with the import ID, got: %v", diags)
+	}
+
+	d := testAdoptWatchData(t, "managed", true)
+	if diags := resourceXrayWatchCreate(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if d.Id() != "test-watch" {
+		t.Errorf("expected the existing watch to be adopted, got ID %s", d.Id())
+	}
+
+	var watch Watch
+	if err := json.Unmarshal(xray.watches["test-watch"], &watch); err != nil {
+		t.Fatal(err)
+	}
+	if watch.GeneralData.Description != "managed" {
+		t.Errorf("expected the adopted watch to be updated, got description %s", watch.GeneralData.Description)
+	}
+}
+
+func TestPolicyCreateAdoptExisting(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.policies["test-policy"] = json.RawMessage(`{"name":"test-policy","type":"security","description":"manual","rules":[]}`)
+
+	policyData := func(adoptExisting bool) *schema.ResourceData {
+		return schema.TestResourceDataRaw(t, resourceXraySecurityPolicyV2().Schema, map[string]interface{}{
+			"name":           "test-policy",
+			"type":           "security",
+			"description":    "managed",
+			"adopt_existing": adoptExisting,
+			"rule": []interface{}{
+				map[string]interface{}{
+					"name":     "rule",
+					"priority": 1,
+					"criteria": []interface{}{
+						map[string]interface{}{"min_severity": "High"},
+					},
+				},
+			},
+		})
+	}
+
+	diags := resourceXrayPolicyCreate(context.Background(), policyData(false), restyClient)
+	if !diags.HasError() || diags[0].Summary != "Policy test-policy already exists" ||
+		!strings.Contains(diags[0].Detail, "terraform import xray_security_policy.<name> test-policy") {
+		t.Fatalf("expected a conflict error with the import ID, got: %v", diags)
+	}
+
+	d := policyData(true)
+	if diags := resourceXrayPolicyCreate(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(xray.policies["test-policy"], &policy); err != nil {
+		t.Fatal(err)
+	}
+	if policy.Description != "managed" || len(*policy.Rules) != 1 {
+		t.Errorf("expected the adopted policy to be updated, got: %+v", policy)
+	}
+}
+
+func TestSameIgnoreRule(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	ignoreRule := IgnoreRule{
+		Notes:     "false positive",
+		ExpiresAt: &expiresAt,
+		IgnoreFilters: IgnoreFilters{
+			CVEs:       []string{"CVE-2021-2", "CVE-2021-1"},
+			Components: []IgnoreFilterNameVersion{{Name: "b"}, {Name: "a", Version: "1.0"}},
+		},
+	}
+
+	existingExpiresAt := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	existing := IgnoreRule{
+		Id:        "c0e5b540",
+		Notes:     "false positive",
+		ExpiresAt: &existingExpiresAt,
+		IgnoreFilters: IgnoreFilters{
+			CVEs:       []string{"CVE-2021-1", "CVE-2021-2"},
+			Components: []IgnoreFilterNameVersion{{Name: "a", Version: "1.0"}, {Name: "b"}},
+		},
+	}
+	if !sameIgnoreRule(existing, ignoreRule) {
+		t.Errorf("expected the ignore rules to match regardless of the order of the filters")
+	}
+
+	existing.IgnoreFilters.CVEs = []string{"CVE-2021-1"}
+	if sameIgnoreRule(existing, ignoreRule) {
+		t.Errorf("expected the ignore rules with different filters not to match")
+	}
+}
+
+func TestIgnoreRuleCreateConflict(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		expectedError string
+		expectedCalls int
+	}{
+		{"conflict", http.StatusConflict, "Ignore rule c0e5b540 already exists", 2},
+		{"other error", http.StatusBadRequest, "400 POST", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xray, restyClient, closeServer := newTestXrayServer(t)
+			defer closeServer()
+			xray.routes["POST /xray/api/v1/ignore_rules"] = jsonResponse(tc.status, `{"error":"failed"}`)
+			xray.routes["GET /xray/api/v1/ignore_rules"] = jsonResponse(http.StatusOK,
+				`{"data":[{"id":"c0e5b540","notes":"false positive","ignore_filters":{"cves":["CVE-2021-1"]}}],"total_count":1}`)
+
+			resource := resourceXrayIgnoreRule()
+			d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+				"notes": "false positive",
+				"cves":  []interface{}{"CVE-2021-1"},
+			})
+
+			diags := resource.CreateContext(context.Background(), d, restyClient)
+			if !diags.HasError() || !strings.Contains(diags[0].Summary, tc.expectedError) {
+				t.Errorf("expected an error containing %q, got: %v", tc.expectedError, diags)
+			}
+			if len(xray.requests) != tc.expectedCalls {
+				t.Errorf("expected %d requests, got %v", tc.expectedCalls, xray.requests)
+			}
+		})
+	}
+}
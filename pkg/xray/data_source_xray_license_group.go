@@ -0,0 +1,76 @@
+package xray
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+	"golang.org/x/exp/slices"
+)
+
+func dataSourceXrayLicenseGroup() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceXrayLicenseGroupRead,
+		Description: "Lists the licenses of one or more categories of the license catalog embedded in the provider, " +
+			"to be used as the `banned_licenses` or `allowed_licenses` of a license policy. It doesn't contact Xray.",
+
+		Schema: map[string]*schema.Schema{
+			"categories": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Description: "The license categories: `permissive`, `weak_copyleft`, `strong_copyleft` or `network_copyleft`.",
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validator.StringInSlice(false, licenseCategories...),
+				},
+			},
+			"include_deprecated": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Default value is `false`. Include the deprecated SPDX identifiers (e.g. `GPL-3.0`), which are still reported by Xray for some components.",
+			},
+			"licenses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The license names of the categories, sorted.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func licenseGroup(categories []string, includeDeprecated bool) []string {
+	var licenses []string
+	for _, license := range licenseCatalog {
+		if !slices.Contains(categories, license.Category) || (license.Deprecated && !includeDeprecated) {
+			continue
+		}
+		licenses = append(licenses, license.Id)
+	}
+	sort.Strings(licenses)
+
+	return licenses
+}
+
+func dataSourceXrayLicenseGroupRead(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	categories := util.CastToStringArr(d.Get("categories").(*schema.Set).List())
+	sort.Strings(categories)
+	includeDeprecated := d.Get("include_deprecated").(bool)
+
+	if err := d.Set("licenses", licenseGroup(categories, includeDeprecated)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(schema.HashString(strings.Join(categories, ",") + strconv.FormatBool(includeDeprecated))))
+
+	return nil
+}
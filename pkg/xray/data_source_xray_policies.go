@@ -0,0 +1,178 @@
+package xray
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+)
+
+func dataSourceXrayPolicies() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceXrayPoliciesRead,
+		Description: "Provides an Xray policies data source. Lists the policies, optionally filtered by type and name.",
+
+		Schema: util.MergeMaps(
+			getProjectKeySchema(false, "The policies are listed from the project scope. Omit to list the global policies."),
+			map[string]*schema.Schema{
+				"type": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Description:      "Only list the policies of this type: `security`, `license`, `operational_risk` or `exposures`.",
+					ValidateDiagFunc: validator.StringInSlice(true, policyTypes...),
+				},
+				"name_regex": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Description:      "Only list the policies with a name matching this regular expression.",
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+				},
+				"names": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "Names of the matching policies, in the order returned by Xray.",
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"policies": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "Summary of the matching policies.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"name": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "Name of the policy",
+							},
+							"type": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "Type of the policy",
+							},
+							"description": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "More verbose description of the policy",
+							},
+							"author": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "User, who created the policy",
+							},
+							"created": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "Creation timestamp",
+							},
+							"modified": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "Modification timestamp",
+							},
+							"rules_count": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "Number of rules of the policy",
+							},
+						},
+					},
+				},
+			},
+		),
+	}
+}
+
+func dataSourceXrayPoliciesRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var policies []Policy
+
+	projectKey := d.Get("project_key").(string)
+	policyType := canonicalEnumValue(d.Get("type").(string), policyTypes)
+	nameRegex := d.Get("name_regex").(string)
+
+	req, err := getRestyRequest(m.(*resty.Client), projectKey)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = req.
+		SetResult(&policies).
+		Get("xray/api/v2/policies")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	matching, err := filterPolicies(policies, policyType, nameRegex)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	names := []string{}
+	summaries := []interface{}{}
+	for _, policy := range matching {
+		names = append(names, policy.Name)
+		summaries = append(summaries, packPolicySummary(policy))
+	}
+
+	if err := d.Set("names", names); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("policies", summaries); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(schema.HashString(fmt.Sprintf("%s/%s/%s", projectKey, policyType, nameRegex))))
+
+	return nil
+}
+
+// filterPolicies returns the policies of policyType with a name matching nameRegex.
+// An empty policyType or nameRegex matches all the policies.
+func filterPolicies(policies []Policy, policyType string, nameRegex string) ([]Policy, error) {
+	var re *regexp.Regexp
+	if len(nameRegex) > 0 {
+		var err error
+		if re, err = regexp.Compile(nameRegex); err != nil {
+			return nil, err
+		}
+	}
+
+	var matching []Policy
+	for _, policy := range policies {
+		if len(policyType) > 0 && !strings.EqualFold(policy.Type, policyType) {
+			continue
+		}
+		if re != nil && !re.MatchString(policy.Name) {
+			continue
+		}
+		matching = append(matching, policy)
+	}
+
+	return matching, nil
+}
+
+func packPolicySummary(policy Policy) map[string]interface{} {
+	rulesCount := 0
+	if policy.Rules != nil {
+		rulesCount = len(*policy.Rules)
+	}
+
+	return map[string]interface{}{
+		"name":        policy.Name,
+		"type":        canonicalEnumValue(policy.Type, policyTypes),
+		"description": policy.Description,
+		"author":      policy.Author,
+		"created":     policy.Created,
+		"modified":    policy.Modified,
+		"rules_count": rulesCount,
+	}
+}
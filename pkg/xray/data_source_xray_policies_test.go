@@ -0,0 +1,81 @@
+package xray
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"github.com/jfrog/terraform-provider-shared/util"
+)
+
+func TestFilterPolicies(t *testing.T) {
+	policies := []Policy{
+		{Name: "team-a-security", Type: "security"},
+		{Name: "team-a-license", Type: "license"},
+		{Name: "team-b-security", Type: "security"},
+	}
+
+	testCases := []struct {
+		policyType string
+		nameRegex  string
+		expected   []string
+	}{
+		{"", "", []string{"team-a-security", "team-a-license", "team-b-security"}},
+		{"security", "", []string{"team-a-security", "team-b-security"}},
+		{"", "^team-a-", []string{"team-a-security", "team-a-license"}},
+		{"security", "^team-b-", []string{"team-b-security"}},
+		{"operational_risk", "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s/%s", tc.policyType, tc.nameRegex), func(t *testing.T) {
+			matching, err := filterPolicies(policies, tc.policyType, tc.nameRegex)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var names []string
+			for _, policy := range matching {
+				names = append(names, policy.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestAccDataSourcePolicies_filterByTypeAndName(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	dataSourceFqrn := "data.xray_policies." + resourceName
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-13-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-13-%d", test.RandomInt())
+
+	config := util.ExecuteTemplate(fqrn, securityPolicyCVSS+`
+	data "xray_policies" "{{ .resource_name }}" {
+		type       = "security"
+		name_regex = "^${xray_security_policy.{{ .resource_name }}.name}$"
+	}`, testData)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceFqrn, "names.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "names.0", testData["policy_name"]),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "policies.0.type", "security"),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "policies.0.description", testData["policy_description"]),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "policies.0.rules_count", "1"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,61 @@
+package xray
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+)
+
+// policyCriteriaSchema has the criteria attributes of all the policy types
+var policyCriteriaSchema = util.MergeMaps(
+	securityCriteriaSchema,
+	licenseCriteriaSchema,
+	operationalRiskCriteriaSchema,
+	exposuresCriteriaSchema,
+)
+
+func dataSourceXrayPolicy() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceXrayPolicyRead,
+		Description: "Provides an Xray policy data source. Reads a policy of any type, including the rules with their criteria and actions.",
+
+		Schema: util.MergeMaps(
+			computedSchema(getPolicySchema(policyCriteriaSchema, licenseActionsSchema)),
+			getProjectKeySchema(false, "The policy is read from the project scope. Omit to read a global policy."),
+			map[string]*schema.Schema{
+				"name": {
+					Type:             schema.TypeString,
+					Required:         true,
+					Description:      "Name of the policy",
+					ValidateDiagFunc: validator.StringIsNotEmpty,
+				},
+			},
+		),
+	}
+}
+
+func dataSourceXrayPolicyRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policy := Policy{}
+
+	req, err := getRestyRequest(m.(*resty.Client), d.Get("project_key").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = req.
+		SetResult(&policy).
+		SetPathParams(map[string]string{
+			"name": d.Get("name").(string),
+		}).
+		Get("xray/api/v2/policies/{name}")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(policy.Name)
+	return packPolicy(policy, d)
+}
@@ -0,0 +1,112 @@
+package xray
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+)
+
+func dataSourceXrayPolicyAssignments() *schema.Resource {
+	watchSchema := resourceXrayWatch().Schema
+
+	return &schema.Resource{
+		ReadContext: dataSourceXrayPolicyAssignmentsRead,
+		Description: "Provides an Xray policy assignments data source. Lists the watches which a policy is assigned to, with the resources they cover and their recipients.",
+
+		Schema: util.MergeMaps(
+			getProjectKeySchema(false, "The watches are listed from the project scope. Omit to list the global watches."),
+			map[string]*schema.Schema{
+				"policy_name": {
+					Type:             schema.TypeString,
+					Required:         true,
+					Description:      "Name of the policy",
+					ValidateDiagFunc: validator.StringIsNotEmpty,
+				},
+				"watch_names": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "Names of the watches which the policy is assigned to, in the order returned by Xray.",
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"watches": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "The watches which the policy is assigned to.",
+					Elem: &schema.Resource{
+						Schema: util.MergeMaps(
+							computedSchema(map[string]*schema.Schema{
+								"name":             watchSchema["name"],
+								"description":      watchSchema["description"],
+								"active":           watchSchema["active"],
+								"watch_resource":   watchSchema["watch_resource"],
+								"watch_recipients": watchSchema["watch_recipients"],
+							}),
+							map[string]*schema.Schema{
+								"policy_type": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "The type the policy is assigned with - security, license or exposures",
+								},
+							},
+						),
+					},
+				},
+			},
+		),
+	}
+}
+
+func dataSourceXrayPolicyAssignmentsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	projectKey := d.Get("project_key").(string)
+	policyName := d.Get("policy_name").(string)
+
+	watches, err := getWatchesAssignedPolicy(m.(*resty.Client), projectKey, policyName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	names := []string{}
+	assignments := []interface{}{}
+	for _, watch := range watches {
+		names = append(names, watch.GeneralData.Name)
+		assignments = append(assignments, packPolicyAssignment(ctx, watch, policyName))
+	}
+
+	if err := d.Set("watch_names", names); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("watches", assignments); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(schema.HashString(fmt.Sprintf("%s/%s", projectKey, policyName))))
+
+	return nil
+}
+
+func packPolicyAssignment(ctx context.Context, watch Watch, policyName string) map[string]interface{} {
+	policyType := ""
+	for _, policy := range watch.AssignedPolicies {
+		if policy.Name == policyName {
+			policyType = canonicalEnumValue(policy.Type, assignedPolicyTypes)
+			break
+		}
+	}
+
+	return map[string]interface{}{
+		"name":             watch.GeneralData.Name,
+		"description":      watch.GeneralData.Description,
+		"active":           watch.GeneralData.Active,
+		"policy_type":      policyType,
+		"watch_resource":   packProjectResources(ctx, watch.ProjectResources),
+		"watch_recipients": watch.WatchRecipients,
+	}
+}
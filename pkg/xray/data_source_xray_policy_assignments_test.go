@@ -0,0 +1,102 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"github.com/jfrog/terraform-provider-shared/util"
+)
+
+func TestDataSourcePolicyAssignmentsRead(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.watches["watch-1"] = json.RawMessage(`{
+		"general_data": {"name": "watch-1", "description": "all repos", "active": true},
+		"project_resources": {"resources": [{"type": "all-repos", "bin_mgr_id": "default", "name": "All Repositories"}]},
+		"assigned_policies": [{"name": "test-policy", "type": "Security"}],
+		"watch_recipients": ["a@example.com"]
+	}`)
+	xray.watches["watch-2"] = json.RawMessage(`{
+		"general_data": {"name": "watch-2", "active": true},
+		"project_resources": {"resources": [{"type": "repository", "bin_mgr_id": "default", "name": "libs-release-local", "repo_type": "local"}]},
+		"assigned_policies": [{"name": "other-policy", "type": "license"}]
+	}`)
+
+	dataSource := dataSourceXrayPolicyAssignments()
+	d := dataSource.TestResourceData()
+	if err := d.Set("policy_name", "test-policy"); err != nil {
+		t.Fatal(err)
+	}
+
+	if diags := dataSource.ReadContext(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if names := d.Get("watch_names").([]interface{}); fmt.Sprint(names) != "[watch-1]" {
+		t.Errorf("expected watch_names [watch-1], got %v", names)
+	}
+	for attr, expected := range map[string]interface{}{
+		"watches.0.name":               "watch-1",
+		"watches.0.policy_type":        "security",
+		"watches.0.active":             true,
+		"watches.0.watch_resource.#":   1,
+		"watches.0.watch_recipients.#": 1,
+		"watches.0.description":        "all repos",
+	} {
+		if actual := d.Get(attr); actual != expected {
+			t.Errorf("expected %s to be %v, got %v", attr, expected, actual)
+		}
+	}
+}
+
+func TestAccDataSourcePolicyAssignments(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	dataSourceFqrn := "data.xray_policy_assignments." + resourceName
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-17-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-17-%d", test.RandomInt())
+	testData["watch_name"] = fmt.Sprintf("xray-watch-%d", test.RandomInt())
+
+	config := util.ExecuteTemplate(fqrn, securityPolicyCVSS+`
+	resource "xray_watch" "{{ .resource_name }}" {
+		name   = "{{ .watch_name }}"
+		active = true
+
+		watch_resource {
+			type = "all-repos"
+		}
+
+		assigned_policy {
+			name = xray_security_policy.{{ .resource_name }}.name
+			type = "security"
+		}
+	}
+
+	data "xray_policy_assignments" "{{ .resource_name }}" {
+		policy_name = xray_security_policy.{{ .resource_name }}.name
+		depends_on  = [xray_watch.{{ .resource_name }}]
+	}`, testData)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceFqrn, "watch_names.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "watch_names.0", testData["watch_name"]),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "watches.0.policy_type", "security"),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "watches.0.watch_resource.#", "1"),
+				),
+			},
+		},
+	})
+}
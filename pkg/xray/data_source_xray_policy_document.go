@@ -0,0 +1,181 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+)
+
+// policyTypeCriteriaSchemas are the criteria attributes supported by each policy type
+var policyTypeCriteriaSchemas = map[string]map[string]*schema.Schema{
+	"security":         securityCriteriaSchema,
+	"license":          licenseCriteriaSchema,
+	"operational_risk": operationalRiskCriteriaSchema,
+	"exposures":        exposuresCriteriaSchema,
+}
+
+func dataSourceXrayPolicyDocument() *schema.Resource {
+	policySchema := getPolicySchema(policyCriteriaSchema, licenseActionsSchema)
+	for _, attr := range []string{"project_key", "author", "created", "modified"} {
+		delete(policySchema, attr)
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceXrayPolicyDocumentRead,
+		Description: "Generates the JSON body of the Xray policies API for a policy, without contacting Xray. " +
+			"The rules take the same `criteria` and `actions` as the `xray_security_policy`, `xray_license_policy`, `xray_operational_risk_policy` and `xray_exposures_policy` resources.",
+
+		Schema: util.MergeMaps(
+			policySchema,
+			map[string]*schema.Schema{
+				"name": {
+					Type:             schema.TypeString,
+					Required:         true,
+					Description:      "Name of the policy",
+					ValidateDiagFunc: validator.StringIsNotEmpty,
+				},
+				"json": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The policy, as sent to the Xray policies API. The JSON is indented, and the keys and the lists of strings (e.g. licenses and mails) are sorted.",
+				},
+				"rules_json": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The `rules` of the policy in the same format as `json`. Can be used as the `rules_json` of the `xray_policy` resource.",
+				},
+			},
+		),
+	}
+}
+
+func dataSourceXrayPolicyDocumentRead(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	policyType := canonicalEnumValue(d.Get("type").(string), policyTypes)
+
+	config, _ := rawConfigValue(d.GetRawConfig()).(map[string]interface{})
+	rules, _ := config["rule"].([]interface{})
+	errs := validateRules(rules, policyType)
+	errs = append(errs, validateRuleAttributesForType(rules, policyType)...)
+	if len(errs) > 0 {
+		return diag.Errorf("invalid policy:\n%s", strings.Join(errs, "\n"))
+	}
+
+	policy, err := unpackPolicy(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// custom_severity is only sent for license policies, see packActions
+	if policyType != "license" {
+		for idx := range *policy.Rules {
+			(*policy.Rules)[idx].Actions.CustomSeverity = ""
+		}
+	}
+
+	policyJSON, err := normalizedJSON(policy)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	rulesJSON, err := normalizedJSON(policy.Rules)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("json", policyJSON); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("rules_json", rulesJSON); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(schema.HashString(policyJSON)))
+
+	return nil
+}
+
+// validateRuleAttributesForType returns an error for each criteria or actions attribute of the rules,
+// read from the raw configuration, which isn't supported by policyType.
+func validateRuleAttributesForType(rules []interface{}, policyType string) []string {
+	var errs []string
+
+	for idx, raw := range rules {
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if criteria, _ := rule["criteria"].([]interface{}); len(criteria) > 0 && criteria[0] != nil {
+			var attrs []string
+			for attr := range criteria[0].(map[string]interface{}) {
+				if _, ok := policyTypeCriteriaSchemas[policyType][attr]; !ok {
+					attrs = append(attrs, attr)
+				}
+			}
+			sort.Strings(attrs)
+			for _, attr := range attrs {
+				errs = append(errs, fmt.Sprintf("rule.%d.criteria.0.%s: attribute '%s' is not supported by '%s' policies", idx, attr, attr, policyType))
+			}
+		}
+
+		if actions, _ := rule["actions"].([]interface{}); len(actions) > 0 && actions[0] != nil && policyType != "license" {
+			if _, ok := actions[0].(map[string]interface{})["custom_severity"]; ok {
+				errs = append(errs, fmt.Sprintf("rule.%d.actions.0.custom_severity: attribute 'custom_severity' is not supported by '%s' policies", idx, policyType))
+			}
+		}
+	}
+
+	return errs
+}
+
+// normalizedJSON returns v as indented JSON with sorted keys and sorted lists of strings, so the
+// document is stable regardless of the order of the set elements.
+func normalizedJSON(v interface{}) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", err
+	}
+
+	normalized, err := json.MarshalIndent(sortStringLists(value), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(normalized), nil
+}
+
+func sortStringLists(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, elem := range value {
+			value[key] = sortStringLists(elem)
+		}
+	case []interface{}:
+		var strs []string
+		for idx, elem := range value {
+			value[idx] = sortStringLists(elem)
+			if s, ok := elem.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		if len(strs) > 0 && len(strs) == len(value) {
+			sort.Strings(strs)
+			for idx, s := range strs {
+				value[idx] = s
+			}
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,140 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestDataSourcePolicyDocument(t *testing.T) {
+	state, err := testPolicyDocumentRead(map[string]interface{}{
+		"name":        "sec-policy",
+		"description": "Security policy",
+		"type":        "Security",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name": "cvss",
+				"criteria": []interface{}{
+					map[string]interface{}{
+						"cvss_range": []interface{}{
+							map[string]interface{}{"from": 1.5, "to": 5.3},
+						},
+					},
+				},
+				"actions": []interface{}{
+					map[string]interface{}{
+						"mails":          []interface{}{"b@example.com", "a@example.com"},
+						"fail_build":     false,
+						"block_download": []interface{}{map[string]interface{}{"unscanned": true, "active": true}},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `{
+  "description": "Security policy",
+  "name": "sec-policy",
+  "rules": [
+    {
+      "actions": {
+        "block_download": {
+          "active": true,
+          "unscanned": true
+        },
+        "block_release_bundle_distribution": true,
+        "build_failure_grace_period_in_days": 0,
+        "create_ticket_enabled": false,
+        "custom_severity": "",
+        "fail_build": false,
+        "mails": [
+          "a@example.com",
+          "b@example.com"
+        ],
+        "notify_deployer": false,
+        "notify_watch_recipients": false,
+        "webhooks": null
+      },
+      "criteria": {
+        "cvss_range": {
+          "from": 1.5,
+          "to": 5.3
+        }
+      },
+      "name": "cvss",
+      "priority": 1
+    }
+  ],
+  "type": "security"
+}`
+	if state.Attributes["json"] != expected {
+		t.Errorf("unexpected policy document\nexpected: %s\n  actual: %s", expected, state.Attributes["json"])
+	}
+}
+
+func TestDataSourcePolicyDocument_attributesOfAnotherType(t *testing.T) {
+	_, err := testPolicyDocumentRead(map[string]interface{}{
+		"name": "lic-policy",
+		"type": "license",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name": "banned",
+				"criteria": []interface{}{
+					map[string]interface{}{
+						"banned_licenses": []interface{}{"GPL-3.0"},
+						"min_severity":    "High",
+					},
+				},
+			},
+		},
+	})
+
+	expected := regexp.MustCompile(`rule\.0\.criteria\.0\.min_severity: attribute 'min_severity' is not supported by 'license' policies`)
+	if err == nil || !expected.MatchString(err.Error()) {
+		t.Errorf("expected error matching %q, got: %v", expected, err)
+	}
+}
+
+// testPolicyDocumentRead reads the policy document data source with config, passing the raw
+// configuration along the planned read, the same way Terraform does.
+func testPolicyDocumentRead(config map[string]interface{}) (*terraform.InstanceState, error) {
+	return testDataSourceRead(dataSourceXrayPolicyDocument(), config, nil)
+}
+
+// testDataSourceRead reads the data source from the configuration, with the raw configuration set as
+// by Terraform
+func testDataSourceRead(dataSource *schema.Resource, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, error) {
+	jsonConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	rawConfig, err := ctyjson.Unmarshal(jsonConfig, dataSource.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := dataSource.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		return nil, err
+	}
+	diff.RawConfig = rawConfig
+
+	state, diags := dataSource.ReadDataApply(context.Background(), diff, meta)
+	for _, d := range diags {
+		if d.Severity == diag.Error {
+			return nil, errors.New(d.Summary)
+		}
+	}
+
+	return state, nil
+}
@@ -0,0 +1,599 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+	"golang.org/x/exp/slices"
+)
+
+// simulatedPolicyTypes are the policy types which can be evaluated locally
+var simulatedPolicyTypes = []string{"security", "license", "operational_risk"}
+
+// PolicyFinding is a hypothetical component and its scan results, to evaluate the policy rules against.
+// The pointers are nil when the value is not known, in which case the criteria on it don't match.
+type PolicyFinding struct {
+	Component             string
+	PackageName           string
+	PackageType           string
+	PackageVersion        string
+	Malicious             bool
+	VulnerabilityId       string
+	Severity              string
+	CVSSScore             *float64
+	FixVersionAvailable   bool
+	Applicable            bool
+	Licenses              []string
+	OperationalRisk       string
+	IsEOL                 bool
+	ReleaseAgeMonths      *int
+	NewerVersions         *int
+	ReleaseCadencePerYear *int
+	CommitsPerYear        *int
+	CommittersPerYear     *int
+}
+
+func dataSourceXrayPolicySimulation() *schema.Resource {
+	ruleSchema := getPolicySchema(policyCriteriaSchema, licenseActionsSchema)["rule"]
+	ruleSchema.Required = false
+	ruleSchema.Optional = true
+	ruleSchema.ExactlyOneOf = []string{"rule", "policy_name"}
+	ruleSchema.RequiredWith = []string{"type"}
+	ruleSchema.Description = "The rules to evaluate, with the same `criteria` and `actions` as the policy resources. Conflicts with `policy_name`."
+	actionsSchema := ruleSchema.Elem.(*schema.Resource).Schema["actions"]
+
+	return &schema.Resource{
+		ReadContext: dataSourceXrayPolicySimulationRead,
+		Description: "Evaluates the rules of a security, license or operational risk policy against hypothetical findings, " +
+			"and returns the rule which would be triggered for each of them, with its actions. " +
+			"The rules are evaluated locally, in the order of their priority, the same way as Xray. Only `policy_name` contacts Xray, to read the rules of the policy.",
+
+		Schema: util.MergeMaps(
+			getProjectKeySchema(false, "Only used with `policy_name`."),
+			map[string]*schema.Schema{
+				"policy_name": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Description:      "Name of an existing policy to read the rules from. Conflicts with `rule`.",
+					ValidateDiagFunc: validator.StringIsNotEmpty,
+				},
+				"type": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Description:      "Type of the policy of the `rule` blocks: `security`, `license` or `operational_risk`.",
+					ValidateDiagFunc: validator.StringInSlice(true, simulatedPolicyTypes...),
+					DiffSuppressFunc: suppressCaseDiff,
+				},
+				"rule": ruleSchema,
+				"finding": {
+					Type:        schema.TypeList,
+					Required:    true,
+					MinItems:    1,
+					Description: "The hypothetical findings to evaluate the rules against. The criteria on a value which isn't set don't match.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"component": {
+								Type:             schema.TypeString,
+								Required:         true,
+								Description:      "Identifier of the component, e.g. `npm://lodash:4.17.20`. It's only used to identify the result.",
+								ValidateDiagFunc: validator.StringIsNotEmpty,
+							},
+							"package_name": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Description: "Name of the package, for the `package_name` criteria.",
+							},
+							"package_type": {
+								Type:             schema.TypeString,
+								Optional:         true,
+								Description:      fmt.Sprintf("Type of the package, for the `package_type` criteria: %s.", strings.Join(packageTypes, ", ")),
+								ValidateDiagFunc: validator.StringInSlice(true, packageTypes...),
+							},
+							"package_version": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Description: "Version of the package, for the `package_versions` criteria.",
+							},
+							"malicious": {
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     false,
+								Description: "Default value is `false`. Whether the package is malicious, for the `malicious_package` criteria.",
+							},
+							"vulnerability_id": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Description: "CVE or Xray ID of the vulnerability, for the `vulnerability_ids` criteria.",
+							},
+							"severity": {
+								Type:             schema.TypeString,
+								Optional:         true,
+								Description:      "Severity of the vulnerability: `Critical`, `High`, `Medium` or `Low`. When omitted, it's computed from `cvss_score`.",
+								ValidateDiagFunc: validator.StringInSlice(true, customSeverities...),
+							},
+							"cvss_score": {
+								Type:             schema.TypeFloat,
+								Optional:         true,
+								Description:      "CVSS score of the vulnerability, for the `cvss_range` criteria.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.FloatBetween(0, 10)),
+							},
+							"fix_version_available": {
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     false,
+								Description: "Default value is `false`. Whether a fix version is available, for the `fix_version_dependant` criteria.",
+							},
+							"applicable": {
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     false,
+								Description: "Default value is `false`. Whether the vulnerability was found applicable by the contextual analysis, for the `applicable_cves_only` criteria.",
+							},
+							"licenses": {
+								Type:        schema.TypeList,
+								Optional:    true,
+								Elem:        &schema.Schema{Type: schema.TypeString},
+								Description: "Licenses of the component. No licenses means the license is unknown.",
+							},
+							"operational_risk": {
+								Type:             schema.TypeString,
+								Optional:         true,
+								Description:      "Operational risk of the component, for the `op_risk_min_risk` criteria: `High`, `Medium` or `Low`.",
+								ValidateDiagFunc: validator.StringInSlice(true, operationalRiskMinRisks...),
+							},
+							"is_eol": {
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     false,
+								Description: "Default value is `false`. Whether the component is end-of-life.",
+							},
+							"release_age_months": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Description: "Age of the release of the component, in months.",
+							},
+							"newer_versions": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Description: "Number of versions released since the version of the component.",
+							},
+							"release_cadence_per_year": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Description: "Number of releases per year.",
+							},
+							"commits_per_year": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Description: "Number of commits per year.",
+							},
+							"committers_per_year": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Description: "Number of committers per year.",
+							},
+						},
+					},
+				},
+				"result": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "The result of the evaluation of each finding, in the order of `finding`.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"component": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "Identifier of the component of the finding.",
+							},
+							"violation": {
+								Type:        schema.TypeBool,
+								Computed:    true,
+								Description: "Whether a rule is triggered by the finding.",
+							},
+							"rule_name": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "Name of the triggered rule, the matching rule with the lowest priority number.",
+							},
+							"rule_priority": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "Priority of the triggered rule.",
+							},
+							"actions": {
+								Type:        actionsSchema.Type,
+								Computed:    true,
+								Description: "Actions of the triggered rule.",
+								Elem: &schema.Resource{
+									Schema: computedSchema(actionsSchema.Elem.(*schema.Resource).Schema),
+								},
+							},
+						},
+					},
+				},
+			},
+		),
+	}
+}
+
+func unpackPolicyFindings(d *schema.ResourceData) []PolicyFinding {
+	// The raw configuration tells which optional numbers are set, as 0 is a valid value
+	config, _ := rawConfigValue(d.GetRawConfig()).(map[string]interface{})
+	rawFindings, _ := config["finding"].([]interface{})
+
+	var findings []PolicyFinding
+	for idx, raw := range d.Get("finding").([]interface{}) {
+		f := raw.(map[string]interface{})
+		rawFinding := map[string]interface{}{}
+		if idx < len(rawFindings) {
+			rawFinding, _ = rawFindings[idx].(map[string]interface{})
+		}
+		optionalInt := func(attr string) *int {
+			if _, ok := rawFinding[attr]; !ok {
+				return nil
+			}
+			v := f[attr].(int)
+			return &v
+		}
+
+		finding := PolicyFinding{
+			Component:             f["component"].(string),
+			PackageName:           f["package_name"].(string),
+			PackageType:           canonicalEnumValue(f["package_type"].(string), packageTypes),
+			PackageVersion:        f["package_version"].(string),
+			Malicious:             f["malicious"].(bool),
+			VulnerabilityId:       f["vulnerability_id"].(string),
+			Severity:              canonicalEnumValue(f["severity"].(string), customSeverities),
+			FixVersionAvailable:   f["fix_version_available"].(bool),
+			Applicable:            f["applicable"].(bool),
+			Licenses:              util.CastToStringArr(f["licenses"].([]interface{})),
+			OperationalRisk:       canonicalEnumValue(f["operational_risk"].(string), operationalRiskMinRisks),
+			IsEOL:                 f["is_eol"].(bool),
+			ReleaseAgeMonths:      optionalInt("release_age_months"),
+			NewerVersions:         optionalInt("newer_versions"),
+			ReleaseCadencePerYear: optionalInt("release_cadence_per_year"),
+			CommitsPerYear:        optionalInt("commits_per_year"),
+			CommittersPerYear:     optionalInt("committers_per_year"),
+		}
+		if _, ok := rawFinding["cvss_score"]; ok {
+			score := f["cvss_score"].(float64)
+			finding.CVSSScore = &score
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
+
+func dataSourceXrayPolicySimulationRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var policyType string
+	var rules []PolicyRule
+
+	if policyName, ok := d.GetOk("policy_name"); ok {
+		policy, _, err := getPolicy(m.(*resty.Client), d.Get("project_key").(string), policyName.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		policyType = canonicalEnumValue(policy.Type, policyTypes)
+		rules = *policy.Rules
+	} else {
+		policyType = canonicalEnumValue(d.Get("type").(string), policyTypes)
+
+		config, _ := rawConfigValue(d.GetRawConfig()).(map[string]interface{})
+		rawRules, _ := config["rule"].([]interface{})
+		errs := validateRules(rawRules, policyType)
+		errs = append(errs, validateRuleAttributesForType(rawRules, policyType)...)
+		if len(errs) > 0 {
+			return diag.Errorf("invalid policy:\n%s", strings.Join(errs, "\n"))
+		}
+
+		var err error
+		rules, err = unpackRules(d.Get("rule").([]interface{}), policyType)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if !slices.Contains(simulatedPolicyTypes, policyType) {
+		return diag.Errorf("policies of type '%s' can't be simulated, only %s policies are supported", policyType, strings.Join(simulatedPolicyTypes, ", "))
+	}
+
+	var results []interface{}
+	for _, finding := range unpackPolicyFindings(d) {
+		result := map[string]interface{}{
+			"component": finding.Component,
+			"violation": false,
+		}
+		if rule := evaluatePolicyRules(rules, policyType, finding); rule != nil {
+			result["violation"] = true
+			result["rule_name"] = rule.Name
+			result["rule_priority"] = rule.Priority
+			result["actions"] = packActions(rule.Actions, policyType == "license")
+		}
+		results = append(results, result)
+	}
+
+	if err := d.Set("result", results); err != nil {
+		return diag.FromErr(err)
+	}
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strconv.Itoa(schema.HashString(string(resultsJSON))))
+
+	return nil
+}
+
+// evaluatePolicyRules returns the rule triggered by the finding: the matching rule with the lowest
+// priority number, as Xray stops at the first matching rule. It returns nil if no rule matches.
+func evaluatePolicyRules(rules []PolicyRule, policyType string, finding PolicyFinding) *PolicyRule {
+	sorted := make([]PolicyRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	for idx := range sorted {
+		criteria := sorted[idx].Criteria
+		if criteria == nil {
+			continue
+		}
+
+		var matches bool
+		switch policyType {
+		case "security":
+			matches = matchesSecurityCriteria(criteria, finding)
+		case "license":
+			matches = matchesLicenseCriteria(criteria, finding)
+		case "operational_risk":
+			matches = matchesOperationalRiskCriteria(criteria, finding)
+		}
+		if matches {
+			return &sorted[idx]
+		}
+	}
+
+	return nil
+}
+
+// severityFromCVSS returns the severity of a CVSS v3 score
+func severityFromCVSS(score float64) string {
+	switch {
+	case score >= 9:
+		return "Critical"
+	case score >= 7:
+		return "High"
+	case score >= 4:
+		return "Medium"
+	case score > 0:
+		return "Low"
+	}
+
+	return ""
+}
+
+// severityRank returns the rank of a severity, from 1 for the lowest, or 0 for an unknown severity
+func severityRank(severity string, severities []string) int {
+	for idx, s := range severities {
+		if strings.EqualFold(s, severity) {
+			return len(severities) - idx
+		}
+	}
+
+	return 0
+}
+
+func matchesSecurityCriteria(criteria *PolicyRuleCriteria, finding PolicyFinding) bool {
+	if len(criteria.PackageName) > 0 && criteria.PackageName != finding.PackageName {
+		return false
+	}
+	if len(criteria.PackageType) > 0 && !strings.EqualFold(criteria.PackageType, finding.PackageType) {
+		return false
+	}
+	if len(criteria.PackageVersions) > 0 && slices.IndexFunc(criteria.PackageVersions, func(versionRange string) bool {
+		return matchesVersionRange(versionRange, finding.PackageVersion)
+	}) < 0 {
+		return false
+	}
+	if criteria.MaliciousPackage {
+		return finding.Malicious
+	}
+
+	if criteria.FixVersionDependant && !finding.FixVersionAvailable {
+		return false
+	}
+	if criteria.ApplicableCVEsOnly && !finding.Applicable {
+		return false
+	}
+
+	if len(criteria.VulnerabilityIds) > 0 {
+		return slices.IndexFunc(criteria.VulnerabilityIds, func(id string) bool {
+			return strings.EqualFold(id, finding.VulnerabilityId)
+		}) >= 0
+	}
+
+	if criteria.CVSSRange != nil {
+		if finding.CVSSScore == nil {
+			return false
+		}
+		if criteria.CVSSRange.From != nil && *finding.CVSSScore < *criteria.CVSSRange.From {
+			return false
+		}
+		if criteria.CVSSRange.To != nil && *finding.CVSSScore > *criteria.CVSSRange.To {
+			return false
+		}
+		return true
+	}
+
+	if len(criteria.MinimumSeverity) == 0 || strings.EqualFold(criteria.MinimumSeverity, "All Severities") {
+		return true
+	}
+	severity := finding.Severity
+	if len(severity) == 0 && finding.CVSSScore != nil {
+		severity = severityFromCVSS(*finding.CVSSScore)
+	}
+	rank := severityRank(severity, customSeverities)
+
+	return rank > 0 && rank >= severityRank(criteria.MinimumSeverity, customSeverities)
+}
+
+// matchesLicenseCriteria reports whether the licenses of the finding violate the criteria. A component
+// with several licenses violates the criteria if any of its licenses does, or, with
+// multi_license_permissive, only if all of them do. An unknown license violates the criteria unless
+// allow_unknown is set.
+func matchesLicenseCriteria(criteria *PolicyRuleCriteria, finding PolicyFinding) bool {
+	if len(finding.Licenses) == 0 {
+		return criteria.AllowUnknown == nil || !*criteria.AllowUnknown
+	}
+
+	violates := func(license string) bool {
+		sameLicense := func(l string) bool { return strings.EqualFold(l, license) }
+		if len(criteria.AllowedLicenses) > 0 {
+			return slices.IndexFunc(criteria.AllowedLicenses, sameLicense) < 0
+		}
+		return slices.IndexFunc(criteria.BannedLicenses, sameLicense) >= 0
+	}
+
+	violations := 0
+	for _, license := range finding.Licenses {
+		if violates(license) {
+			violations++
+		}
+	}
+
+	if criteria.MultiLicensePermissive != nil && *criteria.MultiLicensePermissive {
+		return violations == len(finding.Licenses)
+	}
+	return violations > 0
+}
+
+// matchesOperationalRiskCriteria evaluates either the minimal risk or the custom conditions. The
+// custom conditions on the statistics which are not known for the finding don't match.
+func matchesOperationalRiskCriteria(criteria *PolicyRuleCriteria, finding PolicyFinding) bool {
+	if len(criteria.OperationalRiskMinRisk) > 0 {
+		rank := severityRank(finding.OperationalRisk, operationalRiskMinRisks)
+		return rank > 0 && rank >= severityRank(criteria.OperationalRiskMinRisk, operationalRiskMinRisks)
+	}
+
+	custom := criteria.OperationalRiskCustom
+	if custom == nil {
+		return false
+	}
+
+	greaterThan := func(value *int, threshold int) bool { return value != nil && *value > threshold }
+	lessThan := func(value *int, threshold int) bool { return value != nil && *value < threshold }
+	conditions := []bool{
+		greaterThan(finding.ReleaseAgeMonths, custom.ReleaseDateGreaterThanMonths),
+		greaterThan(finding.NewerVersions, custom.NewerVersionsGreaterThan),
+		lessThan(finding.ReleaseCadencePerYear, custom.ReleaseCadencePerYearLessThan),
+		lessThan(finding.CommitsPerYear, custom.CommitsLessThan),
+		lessThan(finding.CommittersPerYear, custom.CommittersLessThan),
+	}
+	// is_eol is only a condition when it's set
+	if custom.IsEOL {
+		conditions = append(conditions, finding.IsEOL)
+	}
+
+	if custom.UseAndCondition {
+		return !slices.Contains(conditions, false)
+	}
+	return slices.Contains(conditions, true)
+}
+
+// matchesVersionRange reports whether version is in the range, in the format of the `package_versions`
+// criteria: `[1.0]` for an exact version, or `(1.0,2.0]`, `[1.0,)` and `(,2.0)` for an interval.
+func matchesVersionRange(versionRange, version string) bool {
+	if len(version) == 0 || len(versionRange) < 3 {
+		return false
+	}
+
+	lowerInclusive := versionRange[0] == '['
+	upperInclusive := versionRange[len(versionRange)-1] == ']'
+	bounds := strings.Split(versionRange[1:len(versionRange)-1], ",")
+	if len(bounds) == 1 {
+		return compareVersions(version, bounds[0]) == 0
+	}
+
+	lower, upper := bounds[0], bounds[1]
+	if len(lower) > 0 {
+		cmp := compareVersions(version, lower)
+		if cmp < 0 || (cmp == 0 && !lowerInclusive) {
+			return false
+		}
+	}
+	if len(upper) > 0 {
+		cmp := compareVersions(version, upper)
+		if cmp > 0 || (cmp == 0 && !upperInclusive) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareVersions compares the dot separated parts of two versions and returns -1, 0 or 1. The parts are
+// compared by their numeric prefix first, then by their suffix: a part with a suffix (a pre-release such
+// as `3-beta` or `0rc1`) is lower than the same number without suffix.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for idx := 0; idx < len(aParts) || idx < len(bParts); idx++ {
+		aPart, bPart := "0", "0"
+		if idx < len(aParts) {
+			aPart = aParts[idx]
+		}
+		if idx < len(bParts) {
+			bPart = bParts[idx]
+		}
+
+		aNum, aSuffix := splitVersionPart(aPart)
+		bNum, bSuffix := splitVersionPart(bPart)
+		switch {
+		case aNum != bNum:
+			return compareInts(aNum, bNum)
+		case aSuffix == bSuffix:
+			continue
+		case len(aSuffix) == 0:
+			return 1
+		case len(bSuffix) == 0:
+			return -1
+		case aSuffix < bSuffix:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// splitVersionPart splits a part of a version into its numeric prefix (0 if none) and the rest
+func splitVersionPart(part string) (int, string) {
+	end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(part)
+	}
+	num, _ := strconv.Atoi(part[:end])
+	return num, part[end:]
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,342 @@
+package xray
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/jfrog/terraform-provider-shared/test"
+)
+
+func TestDataSourcePolicySimulationSecurity(t *testing.T) {
+	state, err := testDataSourceRead(dataSourceXrayPolicySimulation(), map[string]interface{}{
+		"type": "security",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name":     "critical",
+				"priority": 2,
+				"criteria": []interface{}{map[string]interface{}{"min_severity": "Critical"}},
+				"actions": []interface{}{
+					map[string]interface{}{
+						"fail_build":     true,
+						"block_download": []interface{}{map[string]interface{}{"unscanned": false, "active": true}},
+					},
+				},
+			},
+			map[string]interface{}{
+				"name":     "fixable-cvss",
+				"priority": 1,
+				"criteria": []interface{}{
+					map[string]interface{}{
+						"fix_version_dependant": true,
+						"cvss_range":            []interface{}{map[string]interface{}{"from": 7.0, "to": 10.0}},
+					},
+				},
+			},
+		},
+		"finding": []interface{}{
+			map[string]interface{}{"component": "fixable", "cvss_score": 9.8, "fix_version_available": true},
+			map[string]interface{}{"component": "not-fixable", "cvss_score": 9.8},
+			map[string]interface{}{"component": "low", "severity": "Low", "cvss_score": 2.0, "fix_version_available": true},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]string{
+		"result.#":               "3",
+		"result.0.component":     "fixable",
+		"result.0.violation":     "true",
+		"result.0.rule_name":     "fixable-cvss",
+		"result.0.rule_priority": "1",
+		"result.1.violation":     "true",
+		"result.1.rule_name":     "critical",
+		"result.1.rule_priority": "2",
+		"result.1.actions.#":     "1",
+		"result.2.component":     "low",
+		"result.2.violation":     "false",
+		"result.2.rule_name":     "",
+		"result.2.actions.#":     "0",
+	}
+	for attr, value := range expected {
+		if state.Attributes[attr] != value {
+			t.Errorf("expected %s to be %q, got %q", attr, value, state.Attributes[attr])
+		}
+	}
+}
+
+func TestDataSourcePolicySimulationInvalidRules(t *testing.T) {
+	_, err := testDataSourceRead(dataSourceXrayPolicySimulation(), map[string]interface{}{
+		"type": "license",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name":     "licenses",
+				"criteria": []interface{}{map[string]interface{}{"min_severity": "High"}},
+			},
+		},
+		"finding": []interface{}{
+			map[string]interface{}{"component": "component"},
+		},
+	}, nil)
+	if err == nil || err.Error() != "invalid policy:\nrule.0.criteria.0.min_severity: attribute 'min_severity' is not supported by 'license' policies" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDataSourcePolicySimulationPolicyName(t *testing.T) {
+	policy := Policy{
+		Name: "test-policy",
+		Type: "operational_risk",
+		Rules: &[]PolicyRule{
+			{Name: "high-risk", Priority: 1, Criteria: &PolicyRuleCriteria{OperationalRiskMinRisk: "High"}},
+		},
+	}
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	addTestPolicy(t, xray, policy)
+
+	state, err := testDataSourceRead(dataSourceXrayPolicySimulation(), map[string]interface{}{
+		"policy_name": "test-policy",
+		"finding": []interface{}{
+			map[string]interface{}{"component": "risky", "operational_risk": "High"},
+			map[string]interface{}{"component": "safe", "operational_risk": "Medium"},
+		},
+	}, restyClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state.Attributes["result.0.rule_name"] != "high-risk" || state.Attributes["result.1.violation"] != "false" {
+		t.Errorf("unexpected results: %v", state.Attributes)
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestMatchesLicenseCriteria(t *testing.T) {
+	testCases := []struct {
+		name     string
+		criteria PolicyRuleCriteria
+		licenses []string
+		expected bool
+	}{
+		{"banned", PolicyRuleCriteria{BannedLicenses: []string{"GPL-3.0"}}, []string{"gpl-3.0"}, true},
+		{"not banned", PolicyRuleCriteria{BannedLicenses: []string{"GPL-3.0"}}, []string{"MIT"}, false},
+		{"one of several banned", PolicyRuleCriteria{BannedLicenses: []string{"GPL-3.0"}}, []string{"MIT", "GPL-3.0"}, true},
+		{"one of several banned, permissive", PolicyRuleCriteria{BannedLicenses: []string{"GPL-3.0"}, MultiLicensePermissive: boolPtr(true)}, []string{"MIT", "GPL-3.0"}, false},
+		{"all of several banned, permissive", PolicyRuleCriteria{BannedLicenses: []string{"GPL-3.0", "AGPL-3.0"}, MultiLicensePermissive: boolPtr(true)}, []string{"AGPL-3.0", "GPL-3.0"}, true},
+		{"not allowed", PolicyRuleCriteria{AllowedLicenses: []string{"MIT", "Apache-2.0"}}, []string{"GPL-3.0"}, true},
+		{"allowed", PolicyRuleCriteria{AllowedLicenses: []string{"MIT", "Apache-2.0"}}, []string{"Apache-2.0"}, false},
+		{"unknown", PolicyRuleCriteria{BannedLicenses: []string{"GPL-3.0"}, AllowUnknown: boolPtr(false)}, nil, true},
+		{"unknown allowed", PolicyRuleCriteria{BannedLicenses: []string{"GPL-3.0"}, AllowUnknown: boolPtr(true)}, nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := matchesLicenseCriteria(&tc.criteria, PolicyFinding{Licenses: tc.licenses}); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMatchesSecurityCriteria(t *testing.T) {
+	from, to := 4.0, 6.9
+	testCases := []struct {
+		name     string
+		criteria PolicyRuleCriteria
+		finding  PolicyFinding
+		expected bool
+	}{
+		{"severity above", PolicyRuleCriteria{MinimumSeverity: "Medium"}, PolicyFinding{Severity: "High"}, true},
+		{"severity below", PolicyRuleCriteria{MinimumSeverity: "High"}, PolicyFinding{Severity: "Medium"}, false},
+		{"severity from cvss", PolicyRuleCriteria{MinimumSeverity: "Critical"}, PolicyFinding{CVSSScore: floatPtr(9.1)}, true},
+		{"all severities", PolicyRuleCriteria{MinimumSeverity: "All Severities"}, PolicyFinding{}, true},
+		{"cvss in range", PolicyRuleCriteria{CVSSRange: &PolicyCVSSRange{From: &from, To: &to}}, PolicyFinding{CVSSScore: floatPtr(6.9)}, true},
+		{"cvss out of range", PolicyRuleCriteria{CVSSRange: &PolicyCVSSRange{From: &from, To: &to}}, PolicyFinding{CVSSScore: floatPtr(7.0)}, false},
+		{"cvss unknown", PolicyRuleCriteria{CVSSRange: &PolicyCVSSRange{From: &from, To: &to}}, PolicyFinding{Severity: "Medium"}, false},
+		{"vulnerability id", PolicyRuleCriteria{VulnerabilityIds: []string{"CVE-2021-44228"}}, PolicyFinding{VulnerabilityId: "cve-2021-44228"}, true},
+		{"not applicable", PolicyRuleCriteria{MinimumSeverity: "Low", ApplicableCVEsOnly: true}, PolicyFinding{Severity: "High"}, false},
+		{"malicious", PolicyRuleCriteria{MaliciousPackage: true}, PolicyFinding{Malicious: true}, true},
+		{"not malicious", PolicyRuleCriteria{MaliciousPackage: true}, PolicyFinding{Severity: "Critical"}, false},
+		{"package in range", PolicyRuleCriteria{MinimumSeverity: "Low", PackageName: "lodash", PackageType: "npm", PackageVersions: []string{"(,4.17.21)"}}, PolicyFinding{Severity: "High", PackageName: "lodash", PackageType: "npm", PackageVersion: "4.17.20"}, true},
+		{"package out of range", PolicyRuleCriteria{MinimumSeverity: "Low", PackageName: "lodash", PackageType: "npm", PackageVersions: []string{"(,4.17.21)"}}, PolicyFinding{Severity: "High", PackageName: "lodash", PackageType: "npm", PackageVersion: "4.17.21"}, false},
+		{"other package", PolicyRuleCriteria{MinimumSeverity: "Low", PackageName: "lodash", PackageType: "npm"}, PolicyFinding{Severity: "High", PackageName: "express", PackageType: "npm"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := matchesSecurityCriteria(&tc.criteria, tc.finding); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestMatchesOperationalRiskCriteria(t *testing.T) {
+	custom := OperationalRiskCriteria{
+		ReleaseDateGreaterThanMonths:  12,
+		NewerVersionsGreaterThan:      2,
+		ReleaseCadencePerYearLessThan: 1,
+		CommitsLessThan:               10,
+		CommittersLessThan:            2,
+	}
+	and := custom
+	and.UseAndCondition = true
+	eol := custom
+	eol.UseAndCondition = true
+	eol.IsEOL = true
+	allStats := PolicyFinding{
+		ReleaseAgeMonths:      intPtr(24),
+		NewerVersions:         intPtr(3),
+		ReleaseCadencePerYear: intPtr(0),
+		CommitsPerYear:        intPtr(5),
+		CommittersPerYear:     intPtr(1),
+	}
+
+	testCases := []struct {
+		name     string
+		criteria PolicyRuleCriteria
+		finding  PolicyFinding
+		expected bool
+	}{
+		{"min risk", PolicyRuleCriteria{OperationalRiskMinRisk: "Medium"}, PolicyFinding{OperationalRisk: "High"}, true},
+		{"min risk unknown", PolicyRuleCriteria{OperationalRiskMinRisk: "Low"}, PolicyFinding{}, false},
+		{"or, one condition", PolicyRuleCriteria{OperationalRiskCustom: &custom}, PolicyFinding{CommitsPerYear: intPtr(0)}, true},
+		{"or, no condition", PolicyRuleCriteria{OperationalRiskCustom: &custom}, PolicyFinding{CommitsPerYear: intPtr(10)}, false},
+		{"and, unknown stats", PolicyRuleCriteria{OperationalRiskCustom: &and}, PolicyFinding{CommitsPerYear: intPtr(0)}, false},
+		{"and, all conditions", PolicyRuleCriteria{OperationalRiskCustom: &and}, allStats, true},
+		{"and, not eol", PolicyRuleCriteria{OperationalRiskCustom: &eol}, allStats, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := matchesOperationalRiskCriteria(&tc.criteria, tc.finding); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMatchesVersionRange(t *testing.T) {
+	testCases := []struct {
+		versionRange string
+		version      string
+		expected     bool
+	}{
+		{"[1.2.3]", "1.2.3", true},
+		{"[1.2.3]", "1.2.4", false},
+		{"[1.0,2.0)", "1.0", true},
+		{"[1.0,2.0)", "2.0", false},
+		{"(1.0,2.0]", "1.0.0", false},
+		{"(1.0,2.0]", "1.10", true},
+		{"[1.0,)", "10.0", true},
+		{"(,1.5]", "1.5", true},
+		{"(,1.5]", "", false},
+		{"[1.2.3,)", "1.2.3-beta", false},
+		{"(1.9,2.0)", "1.10-rc", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s", tc.versionRange, tc.version), func(t *testing.T) {
+			if actual := matchesVersionRange(tc.versionRange, tc.version); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.10", "1.9", 1},
+		{"1.2.3", "1.2.3-beta", 1},
+		{"1.2.3-alpha", "1.2.3-beta", -1},
+		{"1.10-rc", "1.9", 1},
+		{"1.10-rc", "1.10", -1},
+		{"2.0.0rc1", "2.0.0", -1},
+		{"1.2.3-beta.2", "1.2.3", -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s", tc.a, tc.b), func(t *testing.T) {
+			if actual := compareVersions(tc.a, tc.b); actual != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, actual)
+			}
+			if actual := compareVersions(tc.b, tc.a); actual != -tc.expected {
+				t.Errorf("expected %d for the reversed comparison, got %d", -tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAccDataSourcePolicySimulation(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("simulation-", "data.xray_policy_simulation")
+	policyName := fmt.Sprintf("terraform-license-policy-%d", test.RandomInt())
+
+	config := fmt.Sprintf(`
+		resource "xray_license_policy" "%[1]s" {
+			name = "%[2]s"
+			type = "license"
+
+			rule {
+				name = "banned"
+				criteria {
+					banned_licenses          = ["GPL-3.0-only"]
+					allow_unknown            = true
+					multi_license_permissive = false
+				}
+				actions {
+					fail_build = true
+					block_download {
+						unscanned = false
+						active    = false
+					}
+				}
+			}
+		}
+
+		data "xray_policy_simulation" "%[1]s" {
+			policy_name = xray_license_policy.%[1]s.name
+
+			finding {
+				component = "gav://org.example:gpl:1.0"
+				licenses  = ["GPL-3.0-only"]
+			}
+			finding {
+				component = "gav://org.example:unknown:1.0"
+			}
+		}
+	`, resourceName, policyName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "result.0.violation", "true"),
+					resource.TestCheckResourceAttr(fqrn, "result.0.rule_name", "banned"),
+					resource.TestCheckResourceAttr(fqrn, "result.0.actions.0.fail_build", "true"),
+					resource.TestCheckResourceAttr(fqrn, "result.1.violation", "false"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,57 @@
+package xray
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"github.com/jfrog/terraform-provider-shared/util"
+)
+
+func TestAccDataSourcePolicy_securityPolicy(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	dataSourceFqrn := "data.xray_policy." + resourceName
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-12-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-12-%d", test.RandomInt())
+
+	config := util.ExecuteTemplate(fqrn, securityPolicyCVSS+`
+	data "xray_policy" "{{ .resource_name }}" {
+		name = xray_security_policy.{{ .resource_name }}.name
+	}`, testData)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					verifySecurityPolicy(dataSourceFqrn, testData, "cvss"),
+					resource.TestCheckResourceAttr(dataSourceFqrn, "type", "security"),
+					resource.TestCheckResourceAttrPair(dataSourceFqrn, "author", fqrn, "author"),
+					resource.TestCheckResourceAttrPair(dataSourceFqrn, "created", fqrn, "created"),
+					resource.TestCheckResourceAttrPair(dataSourceFqrn, "modified", fqrn, "modified"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourcePolicy_notFound(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(`data "xray_policy" "not_found" { name = "not-found-policy-%d" }`, test.RandomInt()),
+				ExpectError: regexp.MustCompile("404"),
+			},
+		},
+	})
+}
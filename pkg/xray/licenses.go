@@ -0,0 +1,141 @@
+package xray
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// License is an entry of the license catalog: the SPDX identifiers, and the other license names known
+// by Xray. The category is empty for the licenses which are not categorized.
+type License struct {
+	Id         string `json:"id"`
+	Category   string `json:"category,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
+}
+
+var licenseCategories = []string{"permissive", "weak_copyleft", "strong_copyleft", "network_copyleft"}
+
+//go:embed licenses.json
+var licenseCatalogJSON []byte
+
+var licenseCatalog = loadLicenseCatalog(licenseCatalogJSON)
+
+func loadLicenseCatalog(catalogJSON []byte) map[string]License {
+	var licenses []License
+	if err := json.Unmarshal(catalogJSON, &licenses); err != nil {
+		panic(fmt.Sprintf("invalid license catalog: %s", err))
+	}
+
+	catalog := map[string]License{}
+	for _, license := range licenses {
+		catalog[license.Id] = license
+	}
+
+	return catalog
+}
+
+// validateLicenseName accepts the license names of the catalog. The deprecated SPDX identifiers are
+// accepted without a warning, as Xray still reports them for some components: replacing them by their
+// replacement would stop matching these components. The error for an unknown name suggests the closest
+// name of the catalog.
+func validateLicenseName(value interface{}, path cty.Path) diag.Diagnostics {
+	name := value.(string)
+
+	if _, ok := licenseCatalog[name]; ok {
+		return nil
+	}
+
+	detail := fmt.Sprintf("'%s' is not a known license name.", name)
+	if suggestion, ok := closestLicenseName(name); ok {
+		detail += fmt.Sprintf(" Did you mean '%s'?", suggestion)
+	}
+	return diag.Diagnostics{{
+		Severity:      diag.Error,
+		Summary:       "Invalid license",
+		Detail:        detail,
+		AttributePath: path,
+	}}
+}
+
+var licenseVersionPrefixRegex = regexp.MustCompile(`v(\d)`)
+
+// normalizeLicenseName ignores the case, the separators and the `v` before the versions, so e.g.
+// `apache v2.0` matches `Apache-2.0`
+func normalizeLicenseName(name string) string {
+	normalized := strings.NewReplacer("-", "", ".", "", " ", "", "_", "").Replace(strings.ToLower(name))
+	return licenseVersionPrefixRegex.ReplaceAllString(normalized, "$1")
+}
+
+// closestLicenseName returns the license name of the catalog with the smallest edit distance to
+// name, if it's close enough to be a typo. The names which are not deprecated win the ties, and a
+// deprecated name is replaced by its replacement.
+func closestLicenseName(name string) (string, bool) {
+	normalized := normalizeLicenseName(name)
+	maxDistance := len(normalized) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var closest License
+	closestDistance := maxDistance + 1
+	for _, license := range licenseCatalog {
+		distance := levenshteinDistance(normalized, normalizeLicenseName(license.Id))
+		if distance < closestDistance ||
+			(distance == closestDistance && len(closest.Id) > 0 && lessLicense(license, closest)) {
+			closest = license
+			closestDistance = distance
+		}
+	}
+
+	if len(closest.ReplacedBy) > 0 {
+		return closest.ReplacedBy, closestDistance <= maxDistance
+	}
+	return closest.Id, closestDistance <= maxDistance
+}
+
+// lessLicense orders the candidate suggestions: the names which are not deprecated first, then by name
+func lessLicense(a, b License) bool {
+	if a.Deprecated != b.Deprecated {
+		return !a.Deprecated
+	}
+	return a.Id < b.Id
+}
+
+func levenshteinDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
@@ -0,0 +1,132 @@
+package xray
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"golang.org/x/exp/slices"
+)
+
+func TestLicenseCatalog(t *testing.T) {
+	for id, license := range licenseCatalog {
+		if len(license.Category) > 0 && !slices.Contains(licenseCategories, license.Category) {
+			t.Errorf("license %s has an unknown category %s", id, license.Category)
+		}
+		if len(license.ReplacedBy) > 0 {
+			if replacement, ok := licenseCatalog[license.ReplacedBy]; !ok || replacement.Deprecated {
+				t.Errorf("license %s is replaced by %s, which is not a valid license", id, license.ReplacedBy)
+			}
+		}
+	}
+}
+
+func TestValidateLicenseName(t *testing.T) {
+	testCases := []struct {
+		name             string
+		expectedSeverity diag.Severity
+		expectedDetail   string
+	}{
+		{"Apache-2.0", -1, ""},
+		{"BSD 3-Clause", -1, ""},
+		{"GPL-3.0", -1, ""},
+		{"wxWindows", -1, ""},
+		{"Apache 2.0", diag.Error, "'Apache 2.0' is not a known license name. Did you mean 'Apache-2.0'?"},
+		{"apache2", diag.Error, "'apache2' is not a known license name. Did you mean 'Apache-2.0'?"},
+		{"GPLv3", diag.Error, "'GPLv3' is not a known license name. Did you mean 'GPL-3.0-only'?"},
+		{"mit", diag.Error, "'mit' is not a known license name. Did you mean 'MIT'?"},
+		{"proprietary-license", diag.Error, "'proprietary-license' is not a known license name."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := validateLicenseName(tc.name, cty.Path{})
+			if tc.expectedSeverity < 0 {
+				if len(diags) > 0 {
+					t.Errorf("unexpected diagnostics: %v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Severity != tc.expectedSeverity || diags[0].Detail != tc.expectedDetail {
+				t.Errorf("unexpected diagnostics: %v", diags)
+			}
+		})
+	}
+}
+
+func TestLicenseGroup(t *testing.T) {
+	copyleft := licenseGroup([]string{"strong_copyleft", "network_copyleft"}, false)
+	for _, expected := range []string{"GPL-3.0-only", "AGPL-3.0-or-later", "OSL-3.0"} {
+		if !slices.Contains(copyleft, expected) {
+			t.Errorf("expected %s in %v", expected, copyleft)
+		}
+	}
+	for _, unexpected := range []string{"GPL-3.0", "LGPL-3.0-only", "MIT"} {
+		if slices.Contains(copyleft, unexpected) {
+			t.Errorf("unexpected %s in %v", unexpected, copyleft)
+		}
+	}
+	if !slices.IsSorted(copyleft) {
+		t.Errorf("expected the licenses to be sorted: %v", copyleft)
+	}
+
+	if withDeprecated := licenseGroup([]string{"strong_copyleft"}, true); !slices.Contains(withDeprecated, "GPL-3.0") {
+		t.Errorf("expected the deprecated GPL-3.0 in %v", withDeprecated)
+	}
+}
+
+func TestAccDataSourceLicenseGroup(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("license-policy-", "xray_license_policy")
+	policyName := fmt.Sprintf("terraform-license-policy-%d", test.RandomInt())
+
+	config := fmt.Sprintf(`
+		data "xray_license_group" "%[1]s" {
+			categories = ["strong_copyleft", "network_copyleft"]
+		}
+
+		resource "xray_license_policy" "%[1]s" {
+			name = "%[2]s"
+			type = "license"
+
+			rule {
+				name = "copyleft"
+				criteria {
+					banned_licenses = data.xray_license_group.%[1]s.licenses
+					allow_unknown   = true
+				}
+				actions {
+					fail_build = false
+					block_download {
+						unscanned = false
+						active    = false
+					}
+				}
+			}
+		}
+	`, resourceName, policyName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.banned_licenses.#", fmt.Sprint(len(licenseGroup([]string{"strong_copyleft", "network_copyleft"}, false)))),
+					resource.TestCheckResourceAttr("data.xray_license_group."+resourceName, "licenses.#", fmt.Sprint(len(licenseGroup([]string{"strong_copyleft", "network_copyleft"}, false)))),
+				),
+			},
+		},
+	})
+}
+
+func TestLicenseNameSuggestionIgnoresSeparators(t *testing.T) {
+	if suggestion, ok := closestLicenseName("bsd_3_clause"); !ok || !strings.EqualFold(normalizeLicenseName(suggestion), "bsd3clause") {
+		t.Errorf("unexpected suggestion %s", suggestion)
+	}
+}
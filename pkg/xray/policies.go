@@ -2,15 +2,21 @@ package xray
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/jfrog/terraform-provider-shared/util"
 	"github.com/jfrog/terraform-provider-shared/validator"
+	"golang.org/x/exp/slices"
 )
 
 var commonActionsSchema = map[string]*schema.Schema{
@@ -89,9 +95,60 @@ var commonActionsSchema = map[string]*schema.Schema{
 	},
 }
 
+// applicableCvesOnlyMinXrayVersion is the first Xray version supporting the `applicable_cves_only` criteria
+const applicableCvesOnlyMinXrayVersion = "3.66.0"
+
+// policyTypes are the canonical policy types, as returned by Xray
+var policyTypes = []string{"security", "license", "operational_risk", "exposures"}
+
+// Canonical values of the severity and risk enums, as returned by Xray
+var minSeverities = []string{"All Severities", "Critical", "High", "Medium", "Low"}
+var customSeverities = []string{"Critical", "High", "Medium", "Low"}
+var operationalRiskMinRisks = []string{"High", "Medium", "Low"}
+var operationalRiskCustomRisks = []string{"high", "medium", "low"}
+
+// packageTypes are the package types supported by Xray, as returned by Xray
+var packageTypes = []string{
+	"alpine", "bower", "cargo", "composer", "conan", "conda", "cran", "debian", "docker", "gems",
+	"generic", "go", "gradle", "huggingfaceml", "maven", "npm", "nuget", "oci", "pypi", "rpm",
+}
+
+// policyEnumAttributes are the case-insensitive enum attributes of the rule criteria and actions
+var policyEnumAttributes = []string{"min_severity", "op_risk_min_risk", "risk", "custom_severity", "package_type"}
+
+var forceDetachOnDestroySchema = map[string]*schema.Schema{
+	"force_detach_on_destroy": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Default value is `false`. Remove the policy from the watches it's assigned to before destroying it. Otherwise, destroying a policy assigned to watches fails and the watches are listed in the error. Only the watches of the same project as the policy (or the global watches for a global policy) are checked. A policy can't be removed from a watch which has no other policy.",
+	},
+}
+
+var ignoreExternalRulesSchema = map[string]*schema.Schema{
+	"ignore_external_rules": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Default value is `false`. Ignore the rules of the policy which are not in the configuration, such as the rules managed by `xray_policy_rule` resources. They are not read into the state and they are kept when the policy is updated. The update fails if a rule of the configuration has the priority of one of these rules.",
+	},
+}
+
+// policyLocks serializes the read-modify-write updates of the rules of a policy, see policyLockKey
+var policyLocks = newMutexKV()
+
+func policyLockKey(projectKey, policyName string) string {
+	return fmt.Sprintf("%s/%s", projectKey, policyName)
+}
+
 var getPolicySchema = func(criteriaSchema map[string]*schema.Schema, actionsSchema map[string]*schema.Schema) map[string]*schema.Schema {
+	criteriaResource := &schema.Resource{
+		Schema: criteriaSchema,
+	}
+	actionsResource := &schema.Resource{
+		Schema: actionsSchema,
+	}
+
 	return util.MergeMaps(
-		getProjectKeySchema(false, ""),
+		getProjectKeySchema(true, "Changing the project key destroys the policy in the old project and creates it in the new one."),
 		map[string]*schema.Schema{
 			"name": {
 				Type:             schema.TypeString,
@@ -109,7 +166,8 @@ var getPolicySchema = func(criteriaSchema map[string]*schema.Schema, actionsSche
 				Type:             schema.TypeString,
 				Required:         true,
 				Description:      "Type of the policy",
-				ValidateDiagFunc: validator.StringInSlice(true, "Security", "License", "Operational_Risk"),
+				ValidateDiagFunc: validator.StringInSlice(true, policyTypes...),
+				DiffSuppressFunc: suppressCaseDiff,
 			},
 			"author": {
 				Type:        schema.TypeString,
@@ -140,9 +198,10 @@ var getPolicySchema = func(criteriaSchema map[string]*schema.Schema, actionsSche
 						},
 						"priority": {
 							Type:             schema.TypeInt,
-							Required:         true,
+							Optional:         true,
 							ValidateDiagFunc: validator.IntAtLeast(1),
-							Description:      "Integer describing the rule priority. Must be at least 1",
+							DiffSuppressFunc: suppressDerivedPriorityDiff,
+							Description:      "Integer describing the rule priority. Must be at least 1. When omitted, the priority is computed from the position of the rule in the list, starting from 1.",
 						},
 						"criteria": {
 							Type:        schema.TypeSet,
@@ -150,18 +209,16 @@ var getPolicySchema = func(criteriaSchema map[string]*schema.Schema, actionsSche
 							MinItems:    1,
 							MaxItems:    1,
 							Description: "The set of security conditions to examine when an scanned artifact is scanned.",
-							Elem: &schema.Resource{
-								Schema: criteriaSchema,
-							},
+							Elem:        criteriaResource,
+							Set:         hashResourceIgnoringCase(criteriaResource, policyEnumAttributes...),
 						},
 						"actions": {
 							Type:        schema.TypeSet,
 							Optional:    true,
 							MaxItems:    1,
 							Description: "Specifies the actions to take once a security policy violation has been triggered.",
-							Elem: &schema.Resource{
-								Schema: actionsSchema,
-							},
+							Elem:        actionsResource,
+							Set:         hashResourceIgnoringCase(actionsResource, policyEnumAttributes...),
 						},
 					},
 				},
@@ -170,6 +227,224 @@ var getPolicySchema = func(criteriaSchema map[string]*schema.Schema, actionsSche
 	)
 }
 
+// policyRulesDiff validates the type and every rule of a policy of policyType at plan time.
+// All offending attributes are reported together, each with its attribute path.
+//
+// The rules are read from the raw configuration: reading a list nested in a set (e.g.
+// `cvss_range` in `criteria`) through the ResourceDiff returns nil elements. Unknown values
+// are read as nil and skipped.
+var policyRulesDiff = func(policyType string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+		rawConfig := diff.GetRawConfig()
+		if rawConfig.IsNull() || !rawConfig.IsKnown() {
+			return nil
+		}
+		config := rawConfigValue(rawConfig).(map[string]interface{})
+
+		projectKey, _ := config["project_key"].(string)
+		name, _ := config["name"].(string)
+		recordPlannedObject("policy", projectKey, name, policyType)
+
+		var errs []string
+
+		if configuredType, ok := config["type"].(string); ok && !strings.EqualFold(configuredType, policyType) {
+			errs = append(errs, fmt.Sprintf("type: attribute 'type' must be '%s' for this resource, got '%s'", policyType, configuredType))
+		}
+
+		rules, _ := config["rule"].([]interface{})
+		errs = append(errs, validateRules(rules, policyType)...)
+
+		if client, ok := meta.(*resty.Client); ok && client != nil && referencesMayHaveChanged(diff, "rule") {
+			errs = append(errs, checkWebhookReferences(ctx, client, webhookReferences(rules))...)
+			if applicableCvesOnlyConfigured(rules) {
+				if err := checkApplicableCvesOnlySupport(client); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("invalid policy:\n%s", strings.Join(errs, "\n"))
+		}
+
+		return nil
+	}
+}
+
+// webhookReferences returns the references to the webhooks in the actions of the rules, read from the raw configuration
+func webhookReferences(rules []interface{}) []objectReference {
+	var references []objectReference
+	for idx, raw := range rules {
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if actions, _ := rule["actions"].([]interface{}); len(actions) > 0 && actions[0] != nil {
+			references = append(references, stringReferences(fmt.Sprintf("rule.%d.actions.0.webhooks", idx), actions[0].(map[string]interface{})["webhooks"])...)
+		}
+	}
+
+	return references
+}
+
+// validateRules validates the rules of a policy of policyType, read from the raw configuration.
+// The errors are prefixed with the attribute path of the offending rule attribute.
+func validateRules(rules []interface{}, policyType string) []string {
+	var errs []string
+
+	ruleNames := map[string]int{}
+	rulePriorities := map[int]int{}
+	for idx, raw := range rules {
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf("rule.%d", idx)
+
+		if name, ok := rule["name"].(string); ok {
+			if other, ok := ruleNames[name]; ok {
+				errs = append(errs, fmt.Sprintf("%s.name: rule name '%s' is already used by rule.%d", path, name, other))
+			} else {
+				ruleNames[name] = idx
+			}
+		}
+
+		// Omitted priorities are computed from the position of the rule, see unpackRules
+		priority := idx + 1
+		if v, ok := rule["priority"].(float64); ok {
+			priority = int(v)
+		}
+		if other, ok := rulePriorities[priority]; ok {
+			errs = append(errs, fmt.Sprintf("%s.priority: priority %d is already used by rule.%d", path, priority, other))
+		} else {
+			rulePriorities[priority] = idx
+		}
+
+		if criteria, _ := rule["criteria"].([]interface{}); len(criteria) > 0 && criteria[0] != nil {
+			errs = append(errs, validateCriteria(criteria[0].(map[string]interface{}), policyType, path+".criteria.0")...)
+		}
+
+		if actions, _ := rule["actions"].([]interface{}); len(actions) > 0 && actions[0] != nil {
+			errs = append(errs, validateActions(actions[0].(map[string]interface{}), path+".actions.0")...)
+		}
+	}
+
+	return errs
+}
+
+func validateCriteria(criteria map[string]interface{}, policyType, path string) []string {
+	var errs []string
+
+	switch policyType {
+	case "security":
+		if cvssRange, _ := criteria["cvss_range"].([]interface{}); len(cvssRange) > 0 && cvssRange[0] != nil {
+			cvss := cvssRange[0].(map[string]interface{})
+			from, fromOk := cvss["from"].(float64)
+			to, toOk := cvss["to"].(float64)
+			if fromOk && toOk && from > to {
+				errs = append(errs, fmt.Sprintf("%s.cvss_range.0.from: 'from' (%v) must not be greater than 'to' (%v)", path, from, to))
+			}
+			if _, ok := criteria["min_severity"].(string); ok {
+				errs = append(errs, fmt.Sprintf("%s.min_severity: attribute 'min_severity' cannot be set together with 'cvss_range'", path))
+			}
+		}
+		if vulnerabilityIds, _ := criteria["vulnerability_ids"].([]interface{}); len(vulnerabilityIds) > 0 {
+			for _, attr := range []string{"min_severity", "cvss_range"} {
+				if v, ok := criteria[attr]; ok && !reflect.DeepEqual(v, []interface{}{}) {
+					errs = append(errs, fmt.Sprintf("%s.vulnerability_ids: attribute 'vulnerability_ids' cannot be set together with '%s'", path, attr))
+				}
+			}
+		}
+		_, hasPackageName := criteria["package_name"].(string)
+		if _, ok := criteria["package_type"].(string); hasPackageName && !ok {
+			errs = append(errs, fmt.Sprintf("%s.package_name: attribute 'package_name' requires 'package_type'", path))
+		}
+		if packageVersions, _ := criteria["package_versions"].([]interface{}); len(packageVersions) > 0 && !hasPackageName {
+			errs = append(errs, fmt.Sprintf("%s.package_versions: attribute 'package_versions' requires 'package_name'", path))
+		}
+		if maliciousPackage, _ := criteria["malicious_package"].(bool); maliciousPackage {
+			for _, attr := range []string{"min_severity", "cvss_range", "vulnerability_ids"} {
+				if v, ok := criteria[attr]; ok && !reflect.DeepEqual(v, []interface{}{}) {
+					errs = append(errs, fmt.Sprintf("%s.malicious_package: attribute 'malicious_package' cannot be set together with '%s'", path, attr))
+				}
+			}
+			for _, attr := range []string{"fix_version_dependant", "applicable_cves_only"} {
+				if v, _ := criteria[attr].(bool); v {
+					errs = append(errs, fmt.Sprintf("%s.malicious_package: attribute 'malicious_package' cannot be set together with '%s'", path, attr))
+				}
+			}
+		}
+	case "license":
+		banned, _ := criteria["banned_licenses"].([]interface{})
+		allowed, _ := criteria["allowed_licenses"].([]interface{})
+		if len(banned) > 0 && len(allowed) > 0 {
+			errs = append(errs, fmt.Sprintf("%s.allowed_licenses: attribute 'allowed_licenses' cannot be set together with 'banned_licenses'", path))
+		}
+	case "exposures":
+		if exposures, _ := criteria["exposures"].([]interface{}); len(exposures) == 0 {
+			errs = append(errs, fmt.Sprintf("%s.exposures: attribute 'exposures' is required for exposures policies", path))
+		}
+	case "operational_risk":
+		_, hasMinRisk := criteria["op_risk_min_risk"].(string)
+		customCriteria, _ := criteria["op_risk_custom"].([]interface{})
+		if hasMinRisk && len(customCriteria) > 0 {
+			errs = append(errs, fmt.Sprintf("%s.op_risk_min_risk: attribute 'op_risk_min_risk' cannot be set together with 'op_risk_custom'", path))
+		}
+	}
+
+	return errs
+}
+
+func validateActions(actions map[string]interface{}, path string) []string {
+	var errs []string
+
+	// fail_build defaults to true, so only an explicit false conflicts with the grace period
+	gracePeriod, _ := actions["build_failure_grace_period_in_days"].(float64)
+	if failBuild, ok := actions["fail_build"].(bool); ok && !failBuild && gracePeriod > 0 {
+		errs = append(errs, fmt.Sprintf("%s.build_failure_grace_period_in_days: attribute 'build_failure_grace_period_in_days' can only be set when 'fail_build' is enabled", path))
+	}
+
+	return errs
+}
+
+// rawConfigValue converts a value of the raw configuration into the shape returned by
+// schema.ResourceData.Get, except that sets are returned as lists and numbers as float64.
+// Null and unknown values are returned as nil.
+func rawConfigValue(val cty.Value) interface{} {
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+
+	valType := val.Type()
+	switch {
+	case valType == cty.String:
+		return val.AsString()
+	case valType == cty.Bool:
+		return val.True()
+	case valType == cty.Number:
+		f, _ := val.AsBigFloat().Float64()
+		return f
+	case valType.IsObjectType() || valType.IsMapType():
+		m := map[string]interface{}{}
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			if v := rawConfigValue(elem); v != nil {
+				m[key.AsString()] = v
+			}
+		}
+		return m
+	case valType.IsListType() || valType.IsSetType() || valType.IsTupleType():
+		l := []interface{}{}
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			l = append(l, rawConfigValue(elem))
+		}
+		return l
+	}
+
+	return nil
+}
+
 type PolicyCVSSRange struct {
 	To   *float64 `json:"to,omitempty"`
 	From *float64 `json:"from,omitempty"`
@@ -188,10 +463,19 @@ type OperationalRiskCriteria struct {
 
 type PolicyRuleCriteria struct {
 	// Security Criteria
-	MinimumSeverity string           `json:"min_severity,omitempty"` // Omitempty is used because the empty field is conflicting with CVSSRange
-	CVSSRange       *PolicyCVSSRange `json:"cvss_range,omitempty"`
+	MinimumSeverity  string           `json:"min_severity,omitempty"` // Omitempty is used because the empty field is conflicting with CVSSRange
+	CVSSRange        *PolicyCVSSRange `json:"cvss_range,omitempty"`
+	VulnerabilityIds []string         `json:"vulnerability_ids,omitempty"` // Omitempty is used because the field is conflicting with MinimumSeverity and CVSSRange
 	// Omitempty is used in FixVersionDependant because an empty field throws an error in Xray below 3.44.3
 	FixVersionDependant bool `json:"fix_version_dependant,omitempty"`
+	// Omitempty is used in ApplicableCVEsOnly because the field is not supported by older Xray versions
+	ApplicableCVEsOnly bool `json:"applicable_cves_only,omitempty"`
+	// Omitempty is used in MaliciousPackage because the field is conflicting with all the other security criteria
+	MaliciousPackage bool `json:"malicious_package,omitempty"`
+	// Omitempty is used in the package fields because the rule applies to all the packages when they are not set
+	PackageName     string   `json:"package_name,omitempty"`
+	PackageType     string   `json:"package_type,omitempty"`
+	PackageVersions []string `json:"package_versions,omitempty"`
 	// We use pointer for CVSSRange to address nil-verification for non-primitive types.
 	// Unlike primitive types, when the non-primitive type in the struct is set
 	// to nil, the empty key will be created in the JSON body anyway.
@@ -207,11 +491,22 @@ type PolicyRuleCriteria struct {
 	BannedLicenses         []string `json:"banned_licenses,omitempty"`
 	AllowedLicenses        []string `json:"allowed_licenses,omitempty"`
 
+	// Exposures criteria
+	Exposures *PolicyExposures `json:"exposures,omitempty"`
+
 	// Operational Risk custom criteria
 	OperationalRiskCustom  *OperationalRiskCriteria `json:"op_risk_custom,omitempty"`
 	OperationalRiskMinRisk string                   `json:"op_risk_min_risk,omitempty"`
 }
 
+type PolicyExposures struct {
+	MinSeverity  string `json:"min_severity"`
+	Secrets      bool   `json:"secrets"`
+	Applications bool   `json:"applications"`
+	Services     bool   `json:"services"`
+	Iac          bool   `json:"iac"`
+}
+
 type BlockDownloadSettings struct {
 	Unscanned bool `json:"unscanned"`
 	Active    bool `json:"active"`
@@ -249,12 +544,19 @@ type Policy struct {
 	Modified    string        `json:"modified,omitempty"` // Omitempty is used because the field is computed
 }
 
+// PolicyJSON is a policy with its rules as JSON, so the rules which aren't managed by a resource are
+// sent back to Xray unchanged, including the attributes unknown to the provider
+type PolicyJSON struct {
+	Policy
+	Rules []json.RawMessage `json:"rules"`
+}
+
 func unpackPolicy(d *schema.ResourceData) (*Policy, error) {
 	policy := new(Policy)
 
 	policy.Name = d.Get("name").(string)
 	if v, ok := d.GetOk("type"); ok {
-		policy.Type = v.(string)
+		policy.Type = canonicalEnumValue(v.(string), policyTypes)
 	}
 	if v, ok := d.GetOk("project_key"); ok {
 		policy.ProjectKey = v.(string)
@@ -271,18 +573,40 @@ func unpackPolicy(d *schema.ResourceData) (*Policy, error) {
 	return policy, err
 }
 
+// suppressDerivedPriorityDiff ignores an omitted priority when the priority in the state is the position of
+// the rule, so the plan uses the same priority as unpackRules and validateRules
+var suppressDerivedPriorityDiff = func(key, old, new string, _ *schema.ResourceData) bool {
+	if new != "" && new != "0" {
+		return false
+	}
+	// The key is rule.<index>.priority
+	parts := strings.Split(key, ".")
+	if len(parts) < 2 {
+		return false
+	}
+	idx, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return false
+	}
+	return old == strconv.Itoa(idx+1)
+}
+
 func unpackRules(configured []interface{}, policyType string) (policyRules []PolicyRule, err error) {
 	var rules []PolicyRule
 
 	if configured != nil {
-		for _, raw := range configured {
+		for idx, raw := range configured {
 			rule := new(PolicyRule)
 			data := raw.(map[string]interface{})
 			rule.Name = data["name"].(string)
 			rule.Priority = data["priority"].(int)
+			// Priority is omitted in the configuration (or not known yet), use the position in the list
+			if rule.Priority == 0 {
+				rule.Priority = idx + 1
+			}
 
 			rule.Criteria, err = unpackCriteria(data["criteria"].(*schema.Set), policyType)
-			if v, ok := data["actions"]; ok {
+			if v, ok := data["actions"]; ok && v.(*schema.Set).Len() > 0 {
 				rule.Actions = unpackActions(v.(*schema.Set))
 			}
 			rules = append(rules, *rule)
@@ -295,14 +619,35 @@ func unpackRules(configured []interface{}, policyType string) (policyRules []Pol
 func unpackSecurityCriteria(tfCriteria map[string]interface{}) *PolicyRuleCriteria {
 	criteria := new(PolicyRuleCriteria)
 
+	// The package criteria narrow down any of the other security criteria
+	if v, ok := tfCriteria["package_name"]; ok {
+		criteria.PackageName = v.(string)
+	}
+	if v, ok := tfCriteria["package_type"]; ok {
+		criteria.PackageType = canonicalEnumValue(v.(string), packageTypes)
+	}
+	if v, ok := tfCriteria["package_versions"]; ok && v.(*schema.Set).Len() > 0 {
+		criteria.PackageVersions = util.CastToStringArr(v.(*schema.Set).List())
+	}
+	if v, ok := tfCriteria["malicious_package"]; ok && v.(bool) {
+		criteria.MaliciousPackage = true
+		return criteria
+	}
 	if v, ok := tfCriteria["fix_version_dependant"]; ok {
 		criteria.FixVersionDependant = v.(bool)
 	}
+	if v, ok := tfCriteria["applicable_cves_only"]; ok {
+		criteria.ApplicableCVEsOnly = v.(bool)
+	}
 
 	// This is also picky about not allowing empty values to be set
+	if v, ok := tfCriteria["vulnerability_ids"]; ok && v.(*schema.Set).Len() > 0 {
+		criteria.VulnerabilityIds = util.CastToStringArr(v.(*schema.Set).List())
+		return criteria
+	}
 	cvss := unpackCVSSRange(tfCriteria["cvss_range"].([]interface{}))
 	if cvss == nil {
-		criteria.MinimumSeverity = tfCriteria["min_severity"].(string)
+		criteria.MinimumSeverity = canonicalEnumValue(tfCriteria["min_severity"].(string), minSeverities)
 	} else {
 		criteria.CVSSRange = cvss
 	}
@@ -352,7 +697,7 @@ func unpackOperationalRiskCustomCriteria(tfCriteria map[string]interface{}) *Ope
 		criteria.CommittersLessThan = v.(int)
 	}
 	if v, ok := tfCriteria["risk"]; ok {
-		criteria.Risk = v.(string)
+		criteria.Risk = canonicalEnumValue(v.(string), operationalRiskCustomRisks)
 	}
 
 	return criteria
@@ -367,7 +712,26 @@ func unpackOperationalRiskCriteria(tfCriteria map[string]interface{}) *PolicyRul
 		}
 	}
 	if v, ok := tfCriteria["op_risk_min_risk"]; ok {
-		criteria.OperationalRiskMinRisk = v.(string)
+		criteria.OperationalRiskMinRisk = canonicalEnumValue(v.(string), operationalRiskMinRisks)
+	}
+
+	return criteria
+}
+
+func unpackExposuresCriteria(tfCriteria map[string]interface{}) *PolicyRuleCriteria {
+	criteria := new(PolicyRuleCriteria)
+	if v, ok := tfCriteria["exposures"]; ok {
+		exposures := v.([]interface{})
+		if len(exposures) > 0 {
+			m := exposures[0].(map[string]interface{})
+			criteria.Exposures = &PolicyExposures{
+				MinSeverity:  canonicalEnumValue(m["min_severity"].(string), minSeverities),
+				Secrets:      m["secrets"].(bool),
+				Applications: m["applications"].(bool),
+				Services:     m["services"].(bool),
+				Iac:          m["iac"].(bool),
+			}
+		}
 	}
 
 	return criteria
@@ -390,6 +754,8 @@ func unpackCriteria(d *schema.Set, policyType string) (*PolicyRuleCriteria, erro
 		criteria = unpackSecurityCriteria(m)
 	} else if policyType == "operational_risk" {
 		criteria = unpackOperationalRiskCriteria(m)
+	} else if policyType == "exposures" {
+		criteria = unpackExposuresCriteria(m)
 	}
 
 	return criteria, nil
@@ -484,12 +850,72 @@ func unpackActions(l *schema.Set) PolicyRuleActions {
 		actions.FailureGracePeriodDays = v.(int)
 	}
 	if v, ok := m["custom_severity"]; ok {
-		actions.CustomSeverity = v.(string)
+		actions.CustomSeverity = canonicalEnumValue(v.(string), customSeverities)
 	}
 
 	return actions
 }
 
+// orderRulesByName returns the rules in the order of the given rule names, so the
+// state follows the order of the configuration rather than the order Xray returns them in.
+// Rules not found in names are appended in the order Xray returned them.
+func orderRulesByName(rules []PolicyRule, names []string) []PolicyRule {
+	var ordered []PolicyRule
+	matched := make([]bool, len(rules))
+
+	for _, name := range names {
+		for idx, rule := range rules {
+			if !matched[idx] && rule.Name == name {
+				ordered = append(ordered, rule)
+				matched[idx] = true
+				break
+			}
+		}
+	}
+
+	for idx, rule := range rules {
+		if !matched[idx] {
+			ordered = append(ordered, rule)
+		}
+	}
+
+	return ordered
+}
+
+func ruleNames(d *schema.ResourceData) []string {
+	return namesOfRules(d.Get("rule").([]interface{}))
+}
+
+func namesOfRules(rules []interface{}) []string {
+	var names []string
+	for _, raw := range rules {
+		if rule, ok := raw.(map[string]interface{}); ok {
+			names = append(names, rule["name"].(string))
+		}
+	}
+
+	return names
+}
+
+// managedRules returns the rules with one of the given names
+func managedRules(rules []PolicyRule, names []string) []PolicyRule {
+	var managed []PolicyRule
+	for _, rule := range rules {
+		if slices.Contains(names, rule.Name) {
+			managed = append(managed, rule)
+		}
+	}
+
+	return managed
+}
+
+// managedRuleNames returns the names of the rules in the configuration or in the state of the policy
+// resource. The other rules of the policy are managed outside of the policy resource.
+func managedRuleNames(d *schema.ResourceData) []string {
+	previous, current := d.GetChange("rule")
+	return append(namesOfRules(previous.([]interface{})), namesOfRules(current.([]interface{}))...)
+}
+
 func packRules(rules []PolicyRule, policyType string) []interface{} {
 	var rs []interface{}
 
@@ -507,6 +933,9 @@ func packRules(rules []PolicyRule, policyType string) []interface{} {
 		case "operational_risk":
 			criteria = packOperationalRiskCriteria(rule.Criteria)
 			isLicense = false
+		case "exposures":
+			criteria = packExposuresCriteria(rule.Criteria)
+			isLicense = false
 		}
 
 		r := map[string]interface{}{
@@ -526,7 +955,7 @@ func packOperationalRiskCriteria(criteria *PolicyRuleCriteria) []interface{} {
 	m := map[string]interface{}{}
 
 	if len(criteria.OperationalRiskMinRisk) > 0 {
-		m["op_risk_min_risk"] = criteria.OperationalRiskMinRisk
+		m["op_risk_min_risk"] = canonicalEnumValue(criteria.OperationalRiskMinRisk, operationalRiskMinRisks)
 	}
 	if criteria.OperationalRiskCustom != nil {
 		m["op_risk_custom"] = packOperationalRiskCustom(criteria.OperationalRiskCustom)
@@ -544,7 +973,25 @@ func packOperationalRiskCustom(custom *OperationalRiskCriteria) []interface{} {
 		"release_cadence_per_year_less_than": custom.ReleaseCadencePerYearLessThan,
 		"commits_less_than":                  custom.CommitsLessThan,
 		"committers_less_than":               custom.CommittersLessThan,
-		"risk":                               custom.Risk,
+		"risk":                               canonicalEnumValue(custom.Risk, operationalRiskCustomRisks),
+	}
+
+	return []interface{}{m}
+}
+
+func packExposuresCriteria(criteria *PolicyRuleCriteria) []interface{} {
+	m := map[string]interface{}{}
+
+	if criteria.Exposures != nil {
+		m["exposures"] = []interface{}{
+			map[string]interface{}{
+				"min_severity": canonicalEnumValue(criteria.Exposures.MinSeverity, minSeverities),
+				"secrets":      criteria.Exposures.Secrets,
+				"applications": criteria.Exposures.Applications,
+				"services":     criteria.Exposures.Services,
+				"iac":          criteria.Exposures.Iac,
+			},
+		}
 	}
 
 	return []interface{}{m}
@@ -570,8 +1017,14 @@ func packSecurityCriteria(criteria *PolicyRuleCriteria) []interface{} {
 	m := map[string]interface{}{}
 	// cvss_range and min_severity are conflicting, only one can be present in the JSON
 	m["cvss_range"] = packCVSSRange(criteria.CVSSRange)
-	m["min_severity"] = criteria.MinimumSeverity
+	m["min_severity"] = canonicalEnumValue(criteria.MinimumSeverity, minSeverities)
 	m["fix_version_dependant"] = criteria.FixVersionDependant
+	m["vulnerability_ids"] = criteria.VulnerabilityIds
+	m["applicable_cves_only"] = criteria.ApplicableCVEsOnly
+	m["malicious_package"] = criteria.MaliciousPackage
+	m["package_name"] = criteria.PackageName
+	m["package_type"] = canonicalEnumValue(criteria.PackageType, packageTypes)
+	m["package_versions"] = criteria.PackageVersions
 
 	return []interface{}{m}
 }
@@ -602,7 +1055,7 @@ func packActions(actions PolicyRuleActions, license bool) []interface{} {
 	}
 
 	if license {
-		m["custom_severity"] = actions.CustomSeverity
+		m["custom_severity"] = canonicalEnumValue(actions.CustomSeverity, customSeverities)
 	}
 
 	return []interface{}{m}
@@ -620,7 +1073,8 @@ func packPolicy(policy Policy, d *schema.ResourceData) diag.Diagnostics {
 	if err := d.Set("name", policy.Name); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("type", policy.Type); err != nil {
+	policyType := canonicalEnumValue(policy.Type, policyTypes)
+	if err := d.Set("type", policyType); err != nil {
 		return diag.FromErr(err)
 	}
 	if len(policy.Description) > 0 {
@@ -637,13 +1091,66 @@ func packPolicy(policy Policy, d *schema.ResourceData) diag.Diagnostics {
 	if err := d.Set("modified", policy.Modified); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("rule", packRules(*policy.Rules, policy.Type)); err != nil {
+	rules := orderRulesByName(*policy.Rules, ruleNames(d))
+	if v, ok := d.GetOk("ignore_external_rules"); ok && v.(bool) {
+		rules = managedRules(rules, ruleNames(d))
+	}
+	if err := d.Set("rule", packRules(rules, policyType)); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return nil
 }
 
+// checkPolicyFeaturesSupport returns an error if the policy uses a criteria which is not supported by
+// the Xray instance, instead of letting Xray ignore it or fail with an unclear message.
+func checkPolicyFeaturesSupport(policy *Policy, client *resty.Client) error {
+	for _, rule := range *policy.Rules {
+		if rule.Criteria != nil && rule.Criteria.ApplicableCVEsOnly {
+			return checkApplicableCvesOnlySupport(client)
+		}
+	}
+
+	return nil
+}
+
+// applicableCvesOnlyConfigured reports whether a rule of the raw configuration sets `applicable_cves_only`
+func applicableCvesOnlyConfigured(rules []interface{}) bool {
+	for _, raw := range rules {
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if criteria, _ := rule["criteria"].([]interface{}); len(criteria) > 0 && criteria[0] != nil {
+			if v, _ := criteria[0].(map[string]interface{})["applicable_cves_only"].(bool); v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func checkApplicableCvesOnlySupport(client *resty.Client) error {
+	supported, xrayVersion, err := isXrayVersionAtLeast(client, applicableCvesOnlyMinXrayVersion)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("attribute 'applicable_cves_only' requires Xray %s or later, got %s", applicableCvesOnlyMinXrayVersion, xrayVersion)
+	}
+
+	entitled, err := isXrayFeatureEntitled(client, "contextual_analysis")
+	if err != nil {
+		return err
+	}
+	if !entitled {
+		return fmt.Errorf("attribute 'applicable_cves_only' requires the contextual analysis entitlement (JFrog Advanced Security)")
+	}
+
+	return nil
+}
+
 func resourceXrayPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	policy, err := unpackPolicy(d)
 	// Warning or errors can be collected in a slice type
@@ -651,13 +1158,32 @@ func resourceXrayPolicyCreate(ctx context.Context, d *schema.ResourceData, m int
 		return diag.FromErr(err)
 	}
 
+	if err := checkPolicyFeaturesSupport(policy, m.(*resty.Client)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	req, err := getRestyRequest(m.(*resty.Client), policy.ProjectKey)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	if d.Get("adopt_existing").(bool) {
+		exists, err := policyExists(m.(*resty.Client), policy.ProjectKey, policy.Name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if exists {
+			tflog.Info(ctx, fmt.Sprintf("Adopting the existing Xray policy (%s)", policy.Name))
+			d.SetId(policy.Name)
+			return resourceXrayPolicyUpdate(ctx, d, m)
+		}
+	}
+
 	_, err = req.SetBody(policy).Post("xray/api/v2/policies")
 	if err != nil {
+		if exists, existsErr := policyExists(m.(*resty.Client), policy.ProjectKey, policy.Name); existsErr == nil && exists {
+			return alreadyExistsDiagnostics(fmt.Sprintf("xray_%s_policy", policy.Type), fmt.Sprintf("Policy %s", policy.Name), policy.Name)
+		}
 		return diag.FromErr(err)
 	}
 
@@ -666,28 +1192,122 @@ func resourceXrayPolicyCreate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceXrayPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policy, resp, err := getPolicy(m.(*resty.Client), d.Get("project_key").(string), d.Id())
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			tflog.Warn(ctx, fmt.Sprintf("Xray policy (%s) not found, removing from state", d.Id()))
+			d.SetId("")
+		}
+		return diag.FromErr(err)
+	}
+	diags := packPolicy(*policy, d)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, packViolationCounts(d, m.(*resty.Client), ViolationsFilters{PolicyName: d.Id()})...)
+}
+
+func policyExists(client *resty.Client, projectKey, name string) (bool, error) {
+	return objectExists(client, projectKey, "xray/api/v2/policies/{name}", map[string]string{"name": name})
+}
+
+// getPolicy reads the policy from Xray. The response is returned to check the status code on error.
+func getPolicy(client *resty.Client, projectKey, name string) (*Policy, *resty.Response, error) {
 	policy := Policy{}
 
-	projectKey := d.Get("project_key").(string)
-	req, err := getRestyRequest(m.(*resty.Client), projectKey)
+	req, err := getRestyRequest(client, projectKey)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, nil, err
 	}
 
 	resp, err := req.
 		SetResult(&policy).
 		SetPathParams(map[string]string{
-			"name": d.Id(),
+			"name": name,
 		}).
 		Get("xray/api/v2/policies/{name}")
 	if err != nil {
-		if resp != nil && resp.StatusCode() == http.StatusNotFound {
-			tflog.Warn(ctx, fmt.Sprintf("Xray policy (%s) not found, removing from state", d.Id()))
-			d.SetId("")
+		return nil, resp, err
+	}
+	if policy.Rules == nil {
+		policy.Rules = &[]PolicyRule{}
+	}
+
+	return &policy, resp, nil
+}
+
+// getPolicyJSON returns the policy with its rules as JSON, along with the rules decoded
+func getPolicyJSON(client *resty.Client, projectKey, name string) (*PolicyJSON, []PolicyRule, *resty.Response, error) {
+	policy := PolicyJSON{}
+
+	req, err := getRestyRequest(client, projectKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	resp, err := req.
+		SetResult(&policy).
+		SetPathParams(map[string]string{
+			"name": name,
+		}).
+		Get("xray/api/v2/policies/{name}")
+	if err != nil {
+		return nil, nil, resp, err
+	}
+
+	var rules []PolicyRule
+	for _, raw := range policy.Rules {
+		var rule PolicyRule
+		if err := json.Unmarshal(raw, &rule); err != nil {
+			return nil, nil, resp, fmt.Errorf("failed to parse a rule of policy %s: %s", name, err)
 		}
-		return diag.FromErr(err)
+		rules = append(rules, rule)
 	}
-	return packPolicy(policy, d)
+
+	return &policy, rules, resp, nil
+}
+
+// replaceRules replaces the rules of the policy named as a managed rule (isManaged) by the managed rule
+// of the same name, or removes them when there is none. The other rules are kept unchanged, and the
+// new managed rules are added after them. An error is returned when a managed rule has the priority
+// of a kept rule, as the priorities of the rules of a policy must be unique.
+func replaceRules(rawRules []json.RawMessage, rules []PolicyRule, managed []PolicyRule, isManaged func(name string) bool) ([]json.RawMessage, error) {
+	keptPriorities := map[int]string{}
+	for _, rule := range rules {
+		if !isManaged(rule.Name) {
+			keptPriorities[rule.Priority] = rule.Name
+		}
+	}
+
+	encoded := map[string]json.RawMessage{}
+	for _, rule := range managed {
+		if other, ok := keptPriorities[rule.Priority]; ok {
+			return nil, fmt.Errorf("priority %d of rule %s is already used by rule %s, which isn't managed by this resource", rule.Priority, rule.Name, other)
+		}
+		raw, err := json.Marshal(rule)
+		if err != nil {
+			return nil, err
+		}
+		encoded[rule.Name] = raw
+	}
+
+	var replaced []json.RawMessage
+	for idx, rule := range rules {
+		if !isManaged(rule.Name) {
+			replaced = append(replaced, rawRules[idx])
+		} else if raw, ok := encoded[rule.Name]; ok {
+			replaced = append(replaced, raw)
+			delete(encoded, rule.Name)
+		}
+	}
+	for _, rule := range managed {
+		if raw, ok := encoded[rule.Name]; ok {
+			replaced = append(replaced, raw)
+		}
+	}
+
+	return replaced, nil
 }
 
 func resourceXrayPolicyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -696,13 +1316,34 @@ func resourceXrayPolicyUpdate(ctx context.Context, d *schema.ResourceData, m int
 		return diag.FromErr(err)
 	}
 
+	if err := checkPolicyFeaturesSupport(policy, m.(*resty.Client)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	policyLocks.Lock(policyLockKey(policy.ProjectKey, d.Id()))
+	defer policyLocks.Unlock(policyLockKey(policy.ProjectKey, d.Id()))
+
+	var body interface{} = policy
+	if d.Get("ignore_external_rules").(bool) {
+		current, currentRules, _, err := getPolicyJSON(m.(*resty.Client), policy.ProjectKey, d.Id())
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		names := managedRuleNames(d)
+		rules, err := replaceRules(current.Rules, currentRules, *policy.Rules, func(name string) bool { return slices.Contains(names, name) })
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		body = PolicyJSON{Policy: *policy, Rules: rules}
+	}
+
 	req, err := getRestyRequest(m.(*resty.Client), policy.ProjectKey)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	_, err = req.
-		SetBody(policy).
+		SetBody(body).
 		SetPathParams(map[string]string{
 			"name": d.Id(),
 		}).
@@ -716,25 +1357,124 @@ func resourceXrayPolicyUpdate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceXrayPolicyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	policy, err := unpackPolicy(d)
+	client := m.(*resty.Client)
+	projectKey := d.Get("project_key").(string)
+
+	watches, err := getWatchesAssignedPolicy(client, projectKey, d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if len(watches) > 0 {
+		var watchNames []string
+		for _, watch := range watches {
+			watchNames = append(watchNames, watch.GeneralData.Name)
+		}
 
-	req, err := getRestyRequest(m.(*resty.Client), policy.ProjectKey)
+		if !d.Get("force_detach_on_destroy").(bool) {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("policy %s is assigned to watches", d.Id()),
+				Detail: fmt.Sprintf("The policy cannot be deleted while it's assigned to the watches: %s. "+
+					"Remove the policy from these watches or set 'force_detach_on_destroy' to remove it automatically.", strings.Join(watchNames, ", ")),
+			}}
+		}
+
+		// Xray requires a watch to have at least one policy, and detaching the policy from the other watches
+		// first would leave the policy partially detached
+		for _, watch := range watches {
+			if len(watch.AssignedPolicies) == 1 {
+				return diag.Diagnostics{{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("policy %s is the only policy of watch %s", d.Id(), watch.GeneralData.Name),
+					Detail: fmt.Sprintf("Removing the policy would leave watch %s without policies. "+
+						"Assign another policy to the watch or destroy the watch first.", watch.GeneralData.Name),
+				}}
+			}
+		}
+
+		for _, watch := range watches {
+			if err := detachPolicyFromWatch(client, projectKey, watch, d.Id()); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		tflog.Info(ctx, fmt.Sprintf("Xray policy (%s) removed from the watches: %s", d.Id(), strings.Join(watchNames, ", ")))
+	}
+
+	req, err := getRestyRequest(client, projectKey)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	// Warning or errors can be collected in a slice type
 	resp, err := req.
 		SetPathParams(map[string]string{
 			"name": d.Id(),
 		}).
 		Delete("xray/api/v2/policies/{name}")
-	if err != nil && resp.StatusCode() == http.StatusInternalServerError {
-		d.SetId("")
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			tflog.Warn(ctx, fmt.Sprintf("Xray policy (%s) not found, removing from state", d.Id()))
+			return nil
+		}
 		return diag.FromErr(err)
 	}
 	return nil
 }
+
+// getWatchesAssignedPolicy returns the watches of the project (or the global watches) which the policy is assigned to.
+// The project watches which a global policy is assigned to aren't returned, as it would require listing the watches
+// of every project.
+func getWatchesAssignedPolicy(client *resty.Client, projectKey, policyName string) ([]Watch, error) {
+	var watches []Watch
+
+	req, err := getRestyRequest(client, projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = req.
+		SetResult(&watches).
+		Get("xray/api/v2/watches")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the watches assigned policy %s: %s", policyName, err)
+	}
+
+	var assigned []Watch
+	for _, watch := range watches {
+		for _, policy := range watch.AssignedPolicies {
+			if policy.Name == policyName {
+				assigned = append(assigned, watch)
+				break
+			}
+		}
+	}
+
+	return assigned, nil
+}
+
+// detachPolicyFromWatch updates the watch with all its assigned policies, except the policy
+func detachPolicyFromWatch(client *resty.Client, projectKey string, watch Watch, policyName string) error {
+	var policies []WatchAssignedPolicy
+	for _, policy := range watch.AssignedPolicies {
+		if policy.Name != policyName {
+			policies = append(policies, policy)
+		}
+	}
+	watch.AssignedPolicies = policies
+
+	req, err := getRestyRequest(client, projectKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = req.
+		SetBody(watch).
+		SetPathParams(map[string]string{
+			"name": watch.GeneralData.Name,
+		}).
+		Put("xray/api/v2/watches/{name}")
+	if err != nil {
+		return fmt.Errorf("failed to remove policy %s from watch %s: %s", policyName, watch.GeneralData.Name, err)
+	}
+
+	return nil
+}
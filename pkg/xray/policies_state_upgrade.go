@@ -0,0 +1,221 @@
+package xray
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceXrayPolicyV0 is the version 0 schema of the security and license policies, inherited
+// from the `xray_policy` resource of the Artifactory provider. Rules were in the plural `rules`
+// attribute, and criteria and actions were lists shared by both policy types.
+func resourceXrayPolicyV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":        {Type: schema.TypeString, Required: true},
+			"description": {Type: schema.TypeString, Optional: true},
+			"type":        {Type: schema.TypeString, Required: true},
+			"author":      {Type: schema.TypeString, Computed: true},
+			"created":     {Type: schema.TypeString, Computed: true},
+			"modified":    {Type: schema.TypeString, Computed: true},
+			"rules": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name":     {Type: schema.TypeString, Required: true},
+						"priority": {Type: schema.TypeInt, Required: true},
+						"criteria": {
+							Type:     schema.TypeList,
+							Required: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"min_severity": {Type: schema.TypeString, Optional: true},
+									"cvss_range": {
+										Type:     schema.TypeList,
+										Optional: true,
+										MaxItems: 1,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"from": {Type: schema.TypeFloat, Required: true},
+												"to":   {Type: schema.TypeFloat, Required: true},
+											},
+										},
+									},
+									"allowed_licenses": {Type: schema.TypeSet, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+									"banned_licenses":  {Type: schema.TypeSet, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+									"allow_unknown":    {Type: schema.TypeBool, Optional: true},
+								},
+							},
+						},
+						"actions": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"mails":      {Type: schema.TypeSet, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+									"webhooks":   {Type: schema.TypeSet, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+									"fail_build": {Type: schema.TypeBool, Optional: true},
+									"block_download": {
+										Type:     schema.TypeList,
+										Required: true,
+										MaxItems: 1,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"unscanned": {Type: schema.TypeBool, Required: true},
+												"active":    {Type: schema.TypeBool, Required: true},
+											},
+										},
+									},
+									"block_release_bundle_distribution":  {Type: schema.TypeBool, Optional: true},
+									"notify_watch_recipients":            {Type: schema.TypeBool, Optional: true},
+									"notify_deployer":                    {Type: schema.TypeBool, Optional: true},
+									"create_ticket_enabled":              {Type: schema.TypeBool, Optional: true},
+									"build_failure_grace_period_in_days": {Type: schema.TypeInt, Optional: true},
+									"custom_severity":                    {Type: schema.TypeString, Optional: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// policyStateUpgradeV0 reshapes a v0 policy state into the current layout:
+// `rules` is renamed to `rule`, criteria and actions only keep the attributes of the policy type
+// (with the defaults of the current schema for the attributes added since).
+func policyStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	policyType, _ := rawState["type"].(string)
+	policyType = canonicalEnumValue(policyType, policyTypes)
+	rawState["type"] = policyType
+
+	rules, ok := rawState["rules"]
+	if !ok {
+		rules = rawState["rule"]
+	}
+	delete(rawState, "rules")
+
+	var upgradedRules []interface{}
+	for idx, raw := range asList(rules) {
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected rule %v in policy state", raw)
+		}
+
+		if priority, ok := rule["priority"].(float64); !ok || priority < 1 {
+			rule["priority"] = idx + 1
+		}
+
+		var criteria map[string]interface{}
+		if l := asList(rule["criteria"]); len(l) > 0 {
+			criteria, _ = l[0].(map[string]interface{})
+		}
+		switch policyType {
+		case "security":
+			rule["criteria"] = []interface{}{upgradeSecurityCriteriaV0(criteria)}
+		case "license":
+			rule["criteria"] = []interface{}{upgradeLicenseCriteriaV0(criteria)}
+		default:
+			return nil, fmt.Errorf("unsupported policy type '%s' in policy state", policyType)
+		}
+
+		if l := asList(rule["actions"]); len(l) > 0 {
+			actions, _ := l[0].(map[string]interface{})
+			rule["actions"] = []interface{}{upgradeActionsV0(actions, policyType == "license")}
+		} else {
+			delete(rule, "actions")
+		}
+
+		upgradedRules = append(upgradedRules, rule)
+	}
+	rawState["rule"] = upgradedRules
+
+	return rawState, nil
+}
+
+func upgradeSecurityCriteriaV0(criteria map[string]interface{}) map[string]interface{} {
+	upgraded := map[string]interface{}{
+		"min_severity":          "",
+		"cvss_range":            []interface{}{},
+		"fix_version_dependant": false,
+	}
+
+	if cvssRange := asList(criteria["cvss_range"]); len(cvssRange) > 0 && cvssRange[0] != nil {
+		upgraded["cvss_range"] = cvssRange
+	} else if minSeverity, ok := criteria["min_severity"].(string); ok {
+		upgraded["min_severity"] = canonicalEnumValue(minSeverity, minSeverities)
+	}
+	if v, ok := criteria["fix_version_dependant"].(bool); ok {
+		upgraded["fix_version_dependant"] = v
+	}
+
+	return upgraded
+}
+
+func upgradeLicenseCriteriaV0(criteria map[string]interface{}) map[string]interface{} {
+	upgraded := map[string]interface{}{
+		"allowed_licenses":         asList(criteria["allowed_licenses"]),
+		"banned_licenses":          asList(criteria["banned_licenses"]),
+		"allow_unknown":            true,
+		"multi_license_permissive": false,
+	}
+
+	if v, ok := criteria["allow_unknown"].(bool); ok {
+		upgraded["allow_unknown"] = v
+	}
+	if v, ok := criteria["multi_license_permissive"].(bool); ok {
+		upgraded["multi_license_permissive"] = v
+	}
+
+	return upgraded
+}
+
+func upgradeActionsV0(actions map[string]interface{}, isLicense bool) map[string]interface{} {
+	upgraded := map[string]interface{}{
+		"webhooks":                           asList(actions["webhooks"]),
+		"mails":                              asList(actions["mails"]),
+		"block_download":                     asList(actions["block_download"]),
+		"block_release_bundle_distribution":  true,
+		"fail_build":                         true,
+		"notify_deployer":                    false,
+		"notify_watch_recipients":            false,
+		"create_ticket_enabled":              false,
+		"build_failure_grace_period_in_days": 0,
+	}
+
+	for _, attr := range []string{"block_release_bundle_distribution", "fail_build", "notify_deployer", "notify_watch_recipients", "create_ticket_enabled"} {
+		if v, ok := actions[attr].(bool); ok {
+			upgraded[attr] = v
+		}
+	}
+	if v, ok := actions["build_failure_grace_period_in_days"].(float64); ok {
+		upgraded["build_failure_grace_period_in_days"] = v
+	}
+
+	if isLicense {
+		upgraded["custom_severity"] = "High"
+		if v, ok := actions["custom_severity"].(string); ok && len(v) > 0 {
+			upgraded["custom_severity"] = canonicalEnumValue(v, customSeverities)
+		}
+	}
+
+	return upgraded
+}
+
+// asList returns v as a list, and nil as an empty list
+func asList(v interface{}) []interface{} {
+	if value, ok := v.([]interface{}); ok {
+		return value
+	}
+
+	return []interface{}{}
+}
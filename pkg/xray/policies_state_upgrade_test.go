@@ -0,0 +1,96 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestPolicyStateUpgradeV0(t *testing.T) {
+	testCases := []struct {
+		name     string
+		resource *schema.Resource
+		fixture  string
+	}{
+		{"security", resourceXraySecurityPolicyV2(), "security_policy_state"},
+		{"license", resourceXrayLicensePolicyV2(), "license_policy_state"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawState := readStateFixture(t, tc.fixture+"_v0.json", 0)
+			expected := readStateFixture(t, tc.fixture+"_v1.json", 1)
+
+			upgraded, err := policyStateUpgradeV0(context.Background(), rawState, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// round-trip through JSON, the same way the SDK stores the upgraded state
+			upgradedJSON, err := json.Marshal(upgraded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual map[string]interface{}
+			if err := json.Unmarshal(upgradedJSON, &actual); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("unexpected upgraded state\nexpected: %v\n  actual: %v", expected, actual)
+			}
+
+			if _, err := ctyjson.Unmarshal(upgradedJSON, tc.resource.CoreConfigSchema().ImpliedType()); err != nil {
+				t.Errorf("upgraded state doesn't match the current schema: %s", err)
+			}
+		})
+	}
+}
+
+func TestPolicyStateUpgradeV0_unsupportedType(t *testing.T) {
+	rawState := map[string]interface{}{
+		"type":  "operational_risk",
+		"rules": []interface{}{map[string]interface{}{"name": "rule"}},
+	}
+
+	if _, err := policyStateUpgradeV0(context.Background(), rawState, nil); err == nil {
+		t.Error("expected an error for an unsupported policy type")
+	}
+}
+
+// readStateFixture returns the attributes of the resource of a state file of Terraform, whose schema
+// version must be schemaVersion. The fixtures have the format of the state files, so the states saved
+// with a release of the provider can be used as is.
+func readStateFixture(t *testing.T, name string, schemaVersion int) map[string]interface{} {
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var state struct {
+		Resources []struct {
+			Instances []struct {
+				SchemaVersion int                    `json:"schema_version"`
+				Attributes    map[string]interface{} `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(content, &state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Resources) != 1 || len(state.Resources[0].Instances) != 1 {
+		t.Fatalf("expected a single resource instance in %s", name)
+	}
+
+	instance := state.Resources[0].Instances[0]
+	if instance.SchemaVersion != schemaVersion {
+		t.Fatalf("expected schema version %d in %s, got %d", schemaVersion, name, instance.SchemaVersion)
+	}
+
+	return instance.Attributes
+}
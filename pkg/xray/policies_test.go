@@ -0,0 +1,646 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestOrderRulesByName(t *testing.T) {
+	rules := []PolicyRule{
+		{Name: "rule-1", Priority: 1},
+		{Name: "rule-2", Priority: 2},
+		{Name: "rule-3", Priority: 3},
+	}
+
+	ordered := orderRulesByName(rules, []string{"rule-3", "rule-1"})
+
+	expected := []string{"rule-3", "rule-1", "rule-2"}
+	if len(ordered) != len(expected) {
+		t.Fatalf("expected %d rules, got %d", len(expected), len(ordered))
+	}
+	for idx, name := range expected {
+		if ordered[idx].Name != name {
+			t.Errorf("expected rule %q at index %d, got %q", name, idx, ordered[idx].Name)
+		}
+	}
+}
+
+func TestPolicyRulesDiff(t *testing.T) {
+	testCases := []struct {
+		name          string
+		resource      *schema.Resource
+		config        map[string]interface{}
+		expectedError string
+	}{
+		{
+			name:     "type contradicts resource",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "License",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name":     "rule-1",
+						"criteria": []interface{}{map[string]interface{}{"min_severity": "High"}},
+					},
+				},
+			},
+			expectedError: "type: attribute 'type' must be 'security' for this resource, got 'License'",
+		},
+		{
+			name:     "duplicate rule name and priority",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name":     "rule-1",
+						"priority": 1,
+						"criteria": []interface{}{map[string]interface{}{"min_severity": "High"}},
+					},
+					map[string]interface{}{
+						"name":     "rule-1",
+						"priority": 1,
+						"criteria": []interface{}{map[string]interface{}{"min_severity": "Low"}},
+					},
+				},
+			},
+			expectedError: "rule.1.name: rule name 'rule-1' is already used by rule.0\nrule.1.priority: priority 1 is already used by rule.0",
+		},
+		{
+			name:     "cvss_range from greater than to and combined with min_severity",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"min_severity": "High",
+							"cvss_range":   []interface{}{map[string]interface{}{"from": 7.0, "to": 5.0}},
+						}},
+					},
+				},
+			},
+			expectedError: "rule.0.criteria.0.cvss_range.0.from: 'from' (7) must not be greater than 'to' (5)\nrule.0.criteria.0.min_severity",
+		},
+		{
+			name:     "vulnerability_ids combined with min_severity",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"min_severity":      "High",
+							"vulnerability_ids": []interface{}{"CVE-2021-44228"},
+						}},
+					},
+				},
+			},
+			expectedError: "rule.0.criteria.0.vulnerability_ids: attribute 'vulnerability_ids' cannot be set together with 'min_severity'",
+		},
+		{
+			name:     "vulnerability_ids only",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"vulnerability_ids": []interface{}{"CVE-2021-44228", "XRAY-194080"},
+						}},
+					},
+				},
+			},
+		},
+		{
+			name:     "malicious_package combined with min_severity and applicable_cves_only",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"malicious_package":    true,
+							"min_severity":         "High",
+							"applicable_cves_only": true,
+						}},
+					},
+				},
+			},
+			expectedError: "rule.0.criteria.0.malicious_package: attribute 'malicious_package' cannot be set together with 'min_severity'\nrule.0.criteria.0.malicious_package: attribute 'malicious_package' cannot be set together with 'applicable_cves_only'",
+		},
+		{
+			name:     "malicious_package only",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"malicious_package": true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			name:     "package criteria without package_name and package_type",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"min_severity":     "High",
+							"package_versions": []interface{}{"[1.0.0,2.0.0)"},
+						}},
+					},
+					map[string]interface{}{
+						"name": "rule-2",
+						"criteria": []interface{}{map[string]interface{}{
+							"min_severity": "High",
+							"package_name": "lodash",
+						}},
+					},
+				},
+			},
+			expectedError: "rule.0.criteria.0.package_versions: attribute 'package_versions' requires 'package_name'\nrule.1.criteria.0.package_name: attribute 'package_name' requires 'package_type'",
+		},
+		{
+			name:     "package criteria",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"min_severity":     "High",
+							"package_name":     "lodash",
+							"package_type":     "NPM",
+							"package_versions": []interface{}{"[4.0.0,4.17.21)", "(,3.10.1]"},
+						}},
+					},
+				},
+			},
+		},
+		{
+			name:     "exposures policy without exposures",
+			resource: resourceXrayExposuresPolicy(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "exposures",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name":     "rule-1",
+						"criteria": []interface{}{map[string]interface{}{}},
+					},
+				},
+			},
+			expectedError: "rule.0.criteria.0.exposures: attribute 'exposures' is required for exposures policies",
+		},
+		{
+			name:     "allowed_licenses together with banned_licenses",
+			resource: resourceXrayLicensePolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "license",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name": "rule-1",
+						"criteria": []interface{}{map[string]interface{}{
+							"allowed_licenses": []interface{}{"MIT"},
+							"banned_licenses":  []interface{}{"GPL-3.0"},
+						}},
+					},
+				},
+			},
+			expectedError: "rule.0.criteria.0.allowed_licenses",
+		},
+		{
+			name:     "grace period without fail_build",
+			resource: resourceXrayOperationalRiskPolicy(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "operational_risk",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name":     "rule-1",
+						"criteria": []interface{}{map[string]interface{}{"op_risk_min_risk": "High"}},
+						"actions": []interface{}{map[string]interface{}{
+							"fail_build":                         false,
+							"build_failure_grace_period_in_days": 5,
+							"block_download":                     []interface{}{map[string]interface{}{"unscanned": false, "active": false}},
+						}},
+					},
+				},
+			},
+			expectedError: "rule.0.actions.0.build_failure_grace_period_in_days",
+		},
+		{
+			name:     "valid policy",
+			resource: resourceXraySecurityPolicyV2(),
+			config: map[string]interface{}{
+				"name": "test-policy",
+				"type": "Security",
+				"rule": []interface{}{
+					map[string]interface{}{
+						"name":     "rule-1",
+						"criteria": []interface{}{map[string]interface{}{"cvss_range": []interface{}{map[string]interface{}{"from": 1.0, "to": 5.0}}}},
+					},
+					map[string]interface{}{
+						"name":     "rule-2",
+						"criteria": []interface{}{map[string]interface{}{"min_severity": "Critical"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testPolicyDiff(testCase.resource, testCase.config, nil)
+			if len(testCase.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+				t.Fatalf("expected error containing %q, got: %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
+// testPolicyDiff plans the creation of resource with config. The raw configuration is
+// passed along the (empty) prior state, the same way Terraform does. The planned policy isn't kept
+// for the other tests.
+func testPolicyDiff(resource *schema.Resource, config map[string]interface{}, meta interface{}) error {
+	defer resetPlannedObjects()
+
+	jsonConfig, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	rawConfig, err := ctyjson.Unmarshal(jsonConfig, resource.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		return err
+	}
+
+	state := &terraform.InstanceState{RawConfig: rawConfig}
+	_, err = resource.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), meta)
+	return err
+}
+
+func TestUnpackRulesWithoutActions(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceXraySecurityPolicyV2().Schema, map[string]interface{}{
+		"name": "test-policy",
+		"type": "security",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name": "rule",
+				"criteria": []interface{}{
+					map[string]interface{}{"min_severity": "High"},
+				},
+			},
+		},
+	})
+
+	rules, err := unpackRules(d.Get("rule").([]interface{}), "security")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || !reflect.DeepEqual(rules[0].Actions, PolicyRuleActions{}) {
+		t.Errorf("expected a rule without actions, got %+v", rules)
+	}
+}
+
+func TestSuppressDerivedPriorityDiff(t *testing.T) {
+	testCases := []struct {
+		key      string
+		old      string
+		new      string
+		expected bool
+	}{
+		{key: "rule.0.priority", old: "1", new: "", expected: true},
+		{key: "rule.2.priority", old: "3", new: "0", expected: true},
+		{key: "rule.0.priority", old: "5", new: "", expected: false},
+		{key: "rule.0.priority", old: "1", new: "2", expected: false},
+		{key: "rule.1.priority", old: "1", new: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		if suppressDerivedPriorityDiff(tc.key, tc.old, tc.new, nil) != tc.expected {
+			t.Errorf("%s: %q -> %q: expected suppressed %t", tc.key, tc.old, tc.new, tc.expected)
+		}
+	}
+}
+
+func TestSecurityCriteriaVulnerabilityIds(t *testing.T) {
+	criteria := unpackSecurityCriteria(map[string]interface{}{
+		"min_severity":          "",
+		"cvss_range":            []interface{}{},
+		"fix_version_dependant": false,
+		"vulnerability_ids":     schema.NewSet(schema.HashString, []interface{}{"CVE-2021-44228"}),
+	})
+
+	body, err := json.Marshal(criteria)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"vulnerability_ids":["CVE-2021-44228"]}`; string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	packed := packSecurityCriteria(criteria)[0].(map[string]interface{})
+	if ids := packed["vulnerability_ids"].([]string); len(ids) != 1 || ids[0] != "CVE-2021-44228" {
+		t.Errorf("expected vulnerability_ids to round-trip, got %v", packed["vulnerability_ids"])
+	}
+}
+
+func TestSecurityCriteriaMaliciousPackage(t *testing.T) {
+	criteria := unpackSecurityCriteria(map[string]interface{}{
+		"min_severity":          "",
+		"cvss_range":            []interface{}{},
+		"fix_version_dependant": false,
+		"applicable_cves_only":  false,
+		"vulnerability_ids":     schema.NewSet(schema.HashString, []interface{}{}),
+		"malicious_package":     true,
+	})
+
+	body, err := json.Marshal(criteria)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"malicious_package":true}`; string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	packed := packSecurityCriteria(criteria)[0].(map[string]interface{})
+	if packed["malicious_package"] != true {
+		t.Errorf("expected malicious_package to round-trip, got %v", packed["malicious_package"])
+	}
+}
+
+func TestSecurityCriteriaPackage(t *testing.T) {
+	criteria := unpackSecurityCriteria(map[string]interface{}{
+		"min_severity":          "high",
+		"cvss_range":            []interface{}{},
+		"fix_version_dependant": false,
+		"vulnerability_ids":     schema.NewSet(schema.HashString, []interface{}{}),
+		"package_name":          "lodash",
+		"package_type":          "NPM",
+		"package_versions":      schema.NewSet(schema.HashString, []interface{}{"[4.0.0,4.17.21)"}),
+	})
+
+	body, err := json.Marshal(criteria)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"min_severity":"High","package_name":"lodash","package_type":"npm","package_versions":["[4.0.0,4.17.21)"]}`; string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	packed := packSecurityCriteria(criteria)[0].(map[string]interface{})
+	if packed["package_name"] != "lodash" || packed["package_type"] != "npm" {
+		t.Errorf("expected the package to round-trip, got %v", packed)
+	}
+	if versions := packed["package_versions"].([]string); len(versions) != 1 || versions[0] != "[4.0.0,4.17.21)" {
+		t.Errorf("expected package_versions to round-trip, got %v", packed["package_versions"])
+	}
+}
+
+func TestPackageVersionRangeRegex(t *testing.T) {
+	for versionRange, valid := range map[string]bool{
+		"[1.2.3]":         true,
+		"[1.0.0,2.0.0)":   true,
+		"(1.0.0,2.0.0]":   true,
+		"(,2.0.0]":        true,
+		"[1.0.0,)":        true,
+		"1.2.3":           false,
+		"[,2.0.0]":        false,
+		"[1.0.0,]":        false,
+		"[1.0.0, 2.0.0)":  false,
+		"[1.0.0,2.0.0,3)": false,
+		"(1.2.3)":         false,
+	} {
+		if packageVersionRangeRegex.MatchString(versionRange) != valid {
+			t.Errorf("expected %q to be valid: %v", versionRange, valid)
+		}
+	}
+}
+
+func TestVulnerabilityIdRegex(t *testing.T) {
+	for id, valid := range map[string]bool{
+		"CVE-2021-44228":  true,
+		"CVE-2022-123456": true,
+		"XRAY-194080":     true,
+		"cve-2021-44228":  false,
+		"CVE-21-44228":    false,
+		"XRAY-":           false,
+		"GHSA-jfh8-c2jp":  false,
+	} {
+		if vulnerabilityIdRegex.MatchString(id) != valid {
+			t.Errorf("expected %q to be valid: %v", id, valid)
+		}
+	}
+}
+
+func TestCheckApplicableCvesOnlySupport(t *testing.T) {
+	testCases := []struct {
+		name          string
+		xrayVersion   string
+		entitled      bool
+		expectedError string
+	}{
+		{"supported", "3.67.1", true, ""},
+		{"old Xray version", "3.55.2", true, "requires Xray 3.66.0 or later, got 3.55.2"},
+		{"not entitled", "3.66.0", false, "requires the contextual analysis entitlement"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xray, restyClient, closeServer := newTestXrayServer(t)
+			defer closeServer()
+			xray.routes["GET /xray/api/v1/system/version"] = jsonResponse(http.StatusOK, fmt.Sprintf(`{"xray_version": "%s", "xray_revision": "abc"}`, tc.xrayVersion))
+			xray.routes["GET /xray/api/v1/entitlements/feature/contextual_analysis"] = jsonResponse(http.StatusOK, fmt.Sprintf(`{"feature_id": "contextual_analysis", "entitled": %t}`, tc.entitled))
+
+			err := checkApplicableCvesOnlySupport(restyClient)
+			if len(tc.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("expected error containing %q, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestPolicyRulesDiffApplicableCvesOnlySupport(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.routes["GET /xray/api/v1/system/version"] = jsonResponse(http.StatusOK, `{"xray_version": "3.55.2", "xray_revision": "abc"}`)
+
+	config := map[string]interface{}{
+		"name": "test-policy",
+		"type": "security",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name":     "rule-1",
+				"criteria": []interface{}{map[string]interface{}{"min_severity": "High", "applicable_cves_only": true}},
+			},
+		},
+	}
+
+	err := testPolicyDiff(resourceXraySecurityPolicyV2(), config, restyClient)
+	if err == nil || !strings.Contains(err.Error(), "attribute 'applicable_cves_only' requires Xray 3.66.0 or later, got 3.55.2") {
+		t.Fatalf("expected the unsupported criteria to fail the plan, got: %v", err)
+	}
+}
+
+func TestExposuresCriteria(t *testing.T) {
+	criteria, err := unpackCriteria(schema.NewSet(schema.HashResource(&schema.Resource{Schema: exposuresCriteriaSchema}), []interface{}{
+		map[string]interface{}{
+			"exposures": []interface{}{
+				map[string]interface{}{
+					"min_severity": "high",
+					"secrets":      true,
+					"applications": false,
+					"services":     true,
+					"iac":          false,
+				},
+			},
+		},
+	}), "exposures")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(criteria)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"exposures":{"min_severity":"High","secrets":true,"applications":false,"services":true,"iac":false}}`; string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	packed := packExposuresCriteria(criteria)[0].(map[string]interface{})
+	exposures := packed["exposures"].([]interface{})[0].(map[string]interface{})
+	if exposures["min_severity"] != "High" || exposures["secrets"] != true || exposures["iac"] != false {
+		t.Errorf("expected exposures to round-trip, got %v", exposures)
+	}
+}
+
+func TestPolicyDeleteAssignedToWatches(t *testing.T) {
+	testCases := []struct {
+		name             string
+		forceDetach      bool
+		watches          map[string]string
+		expectedError    string
+		expectedRequests []string
+		expectedPolicies []string
+	}{
+		{
+			name:             "assigned",
+			forceDetach:      false,
+			expectedError:    "The policy cannot be deleted while it's assigned to the watches: watch-1.",
+			expectedRequests: []string{"GET /xray/api/v2/watches"},
+			expectedPolicies: []string{"test-policy", "other-policy"},
+		},
+		{
+			name:        "force detach",
+			forceDetach: true,
+			expectedRequests: []string{
+				"GET /xray/api/v2/watches",
+				"PUT /xray/api/v2/watches/watch-1",
+				"DELETE /xray/api/v2/policies/test-policy",
+			},
+			expectedPolicies: []string{"other-policy"},
+		},
+		{
+			name:        "only policy of a watch",
+			forceDetach: true,
+			watches: map[string]string{
+				"watch-3": `{"general_data": {"name": "watch-3"}, "assigned_policies": [{"name": "test-policy", "type": "security"}]}`,
+			},
+			expectedError:    "Removing the policy would leave watch watch-3 without policies.",
+			expectedRequests: []string{"GET /xray/api/v2/watches"},
+			expectedPolicies: []string{"test-policy", "other-policy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xray, restyClient, closeServer := newTestXrayServer(t)
+			defer closeServer()
+			xray.policies["test-policy"] = json.RawMessage(`{"name": "test-policy", "type": "security", "rules": []}`)
+			xray.watches["watch-1"] = json.RawMessage(`{"general_data": {"name": "watch-1"}, "assigned_policies": [{"name": "test-policy", "type": "security"}, {"name": "other-policy", "type": "license"}]}`)
+			if tc.watches == nil {
+				xray.watches["watch-2"] = json.RawMessage(`{"general_data": {"name": "watch-2"}, "assigned_policies": [{"name": "other-policy", "type": "license"}]}`)
+			}
+			for name, watch := range tc.watches {
+				xray.watches[name] = json.RawMessage(watch)
+			}
+
+			resource := resourceXraySecurityPolicyV2()
+			d := resource.TestResourceData()
+			d.SetId("test-policy")
+			if err := d.Set("force_detach_on_destroy", tc.forceDetach); err != nil {
+				t.Fatal(err)
+			}
+
+			diags := resource.DeleteContext(context.Background(), d, restyClient)
+			if len(tc.expectedError) == 0 {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %v", diags)
+				}
+			} else if !diags.HasError() || !strings.Contains(diags[0].Detail, tc.expectedError) {
+				t.Fatalf("expected error containing %q, got: %v", tc.expectedError, diags)
+			}
+
+			if strings.Join(xray.requests, "\n") != strings.Join(tc.expectedRequests, "\n") {
+				t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(tc.expectedRequests, "\n"), strings.Join(xray.requests, "\n"))
+			}
+
+			var watch Watch
+			if err := json.Unmarshal(xray.watches["watch-1"], &watch); err != nil {
+				t.Fatal(err)
+			}
+			var policyNames []string
+			for _, policy := range watch.AssignedPolicies {
+				policyNames = append(policyNames, policy.Name)
+			}
+			if !reflect.DeepEqual(policyNames, tc.expectedPolicies) {
+				t.Errorf("expected watch-1 to have the policies %v, got %v", tc.expectedPolicies, policyNames)
+			}
+		})
+	}
+}
@@ -45,12 +45,28 @@ func Provider() *schema.Provider {
 				"xray_security_policy":         resourceXraySecurityPolicyV2(),
 				"xray_license_policy":          resourceXrayLicensePolicyV2(),
 				"xray_operational_risk_policy": resourceXrayOperationalRiskPolicy(),
+				"xray_exposures_policy":        resourceXrayExposuresPolicy(),
+				"xray_policy":                  resourceXrayPolicy(),
+				"xray_policy_rule":             resourceXrayPolicyRule(),
+				"xray_policy_set":              resourceXrayPolicySet(),
 				"xray_watch":                   resourceXrayWatch(),
 				"xray_ignore_rule":             resourceXrayIgnoreRule(),
 				"xray_settings":                resourceXraySettings(),
 				"xray_workers_count":           resourceXrayWorkersCount(),
 			},
 		),
+
+		DataSourcesMap: util.AddTelemetry(
+			productId,
+			map[string]*schema.Resource{
+				"xray_policy":             dataSourceXrayPolicy(),
+				"xray_policies":           dataSourceXrayPolicies(),
+				"xray_policy_document":    dataSourceXrayPolicyDocument(),
+				"xray_policy_assignments": dataSourceXrayPolicyAssignments(),
+				"xray_policy_simulation":  dataSourceXrayPolicySimulation(),
+				"xray_license_group":      dataSourceXrayLicenseGroup(),
+			},
+		),
 	}
 
 	p.ConfigureContextFunc = func(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
@@ -0,0 +1,159 @@
+package xray
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/exp/slices"
+)
+
+// objectReference is a reference to a policy, watch or webhook by name, found at the attribute path.
+// The type is only set for the references to policies of a given type.
+type objectReference struct {
+	Path string
+	Name string
+	Type string
+}
+
+// plannedObjects records the policies and watches planned by the provider, so the references to the
+// objects created in the same plan aren't reported as missing. Terraform plans the referenced objects
+// first, as long as they are referenced by expressions rather than by literal names.
+var plannedObjects = struct {
+	sync.Mutex
+	types map[string]string
+}{types: map[string]string{}}
+
+func plannedObjectKey(kind, projectKey, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, projectKey, name)
+}
+
+// recordPlannedObject records a policy or watch of the plan. The type is the type of the policies.
+func recordPlannedObject(kind, projectKey, name, objectType string) {
+	if len(name) == 0 {
+		return
+	}
+
+	plannedObjects.Lock()
+	defer plannedObjects.Unlock()
+	plannedObjects.types[plannedObjectKey(kind, projectKey, name)] = objectType
+}
+
+func getPlannedObject(kind, projectKey, name string) (string, bool) {
+	plannedObjects.Lock()
+	defer plannedObjects.Unlock()
+	objectType, ok := plannedObjects.types[plannedObjectKey(kind, projectKey, name)]
+	return objectType, ok
+}
+
+// referencesMayHaveChanged reports whether the references of the attributes must be checked. They are
+// only checked when they may have changed, to not query Xray on every plan.
+func referencesMayHaveChanged(diff *schema.ResourceDiff, attrs ...string) bool {
+	return diff.Id() == "" || diff.HasChanges(attrs...)
+}
+
+// checkPolicyReferences returns an error for each referenced policy which doesn't exist in the project,
+// or whose type isn't the type of the reference. The policies of the plan are skipped, as they may not
+// exist yet. The errors of the requests other than a missing policy skip the check, as it's only meant
+// to catch the typos before the apply.
+func checkPolicyReferences(ctx context.Context, client *resty.Client, projectKey string, references []objectReference) []string {
+	var errs []string
+	for _, reference := range references {
+		policyType, ok := getPlannedObject("policy", projectKey, reference.Name)
+		if !ok {
+			policy, resp, err := getPolicy(client, projectKey, reference.Name)
+			if err != nil {
+				if resp != nil && resp.StatusCode() == http.StatusNotFound {
+					errs = append(errs, fmt.Sprintf("%s: policy '%s' doesn't exist%s", reference.Path, reference.Name, inProject(projectKey)))
+				} else {
+					tflog.Warn(ctx, fmt.Sprintf("Skipping the check of the reference to policy %s: %s", reference.Name, err))
+				}
+				continue
+			}
+			policyType = policy.Type
+		}
+
+		if len(reference.Type) > 0 && len(policyType) > 0 && reference.Type != policyType {
+			errs = append(errs, fmt.Sprintf("%s: policy '%s' has type '%s', not '%s'", reference.Path, reference.Name, policyType, reference.Type))
+		}
+	}
+
+	return errs
+}
+
+// checkWatchReferences returns an error for each referenced watch which doesn't exist in the project.
+// The watches of the plan are skipped, as they may not exist yet.
+func checkWatchReferences(ctx context.Context, client *resty.Client, projectKey string, references []objectReference) []string {
+	var errs []string
+	for _, reference := range references {
+		if _, ok := getPlannedObject("watch", projectKey, reference.Name); ok {
+			continue
+		}
+
+		exists, err := watchExists(client, projectKey, reference.Name)
+		if err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Skipping the check of the reference to watch %s: %s", reference.Name, err))
+			continue
+		}
+		if !exists {
+			errs = append(errs, fmt.Sprintf("%s: watch '%s' doesn't exist%s", reference.Path, reference.Name, inProject(projectKey)))
+		}
+	}
+
+	return errs
+}
+
+// checkWebhookReferences returns an error for each referenced webhook which isn't configured in Xray.
+// Webhooks aren't managed by the provider, so they can't be created in the same plan.
+func checkWebhookReferences(ctx context.Context, client *resty.Client, references []objectReference) []string {
+	if len(references) == 0 {
+		return nil
+	}
+
+	type Webhook struct {
+		Name string `json:"name"`
+	}
+	var webhooks []Webhook
+
+	_, err := client.R().
+		SetResult(&webhooks).
+		Get("xray/api/v1/webhooks")
+	if err != nil {
+		tflog.Warn(ctx, fmt.Sprintf("Skipping the check of the references to webhooks: %s", err))
+		return nil
+	}
+
+	var errs []string
+	for _, reference := range references {
+		if slices.IndexFunc(webhooks, func(webhook Webhook) bool { return webhook.Name == reference.Name }) < 0 {
+			errs = append(errs, fmt.Sprintf("%s: webhook '%s' doesn't exist", reference.Path, reference.Name))
+		}
+	}
+
+	return errs
+}
+
+func inProject(projectKey string) string {
+	if len(projectKey) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" in project '%s'", projectKey)
+}
+
+// stringReferences returns the references to the objects named by the values of a list or set of strings,
+// read from the raw configuration. The unknown values are skipped.
+func stringReferences(path string, values interface{}) []objectReference {
+	var references []objectReference
+	list, _ := values.([]interface{})
+	for idx, value := range list {
+		if name, ok := value.(string); ok {
+			references = append(references, objectReference{Path: fmt.Sprintf("%s.%d", path, idx), Name: name})
+		}
+	}
+
+	return references
+}
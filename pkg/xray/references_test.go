@@ -0,0 +1,110 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// resetPlannedObjects clears the objects recorded by a test, so the other tests don't depend on their order
+func resetPlannedObjects() {
+	plannedObjects.Lock()
+	defer plannedObjects.Unlock()
+	plannedObjects.types = map[string]string{}
+}
+
+func TestCheckPolicyReferences(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	defer resetPlannedObjects()
+	xray.policies["license-policy"] = json.RawMessage(`{"name":"license-policy","type":"license","rules":[]}`)
+	recordPlannedObject("policy", "", "planned-policy", "security")
+
+	errs := checkPolicyReferences(context.Background(), restyClient, "", []objectReference{
+		{Path: "assigned_policy.0.name", Name: "license-policy", Type: "license"},
+		{Path: "assigned_policy.1.name", Name: "planned-policy", Type: "security"},
+		{Path: "assigned_policy.2.name", Name: "licence-policy", Type: "license"},
+		{Path: "assigned_policy.3.name", Name: "license-policy", Type: "security"},
+		{Path: "assigned_policy.4.name", Name: "planned-policy", Type: "operational_risk"},
+	})
+
+	expected := []string{
+		"assigned_policy.2.name: policy 'licence-policy' doesn't exist",
+		"assigned_policy.3.name: policy 'license-policy' has type 'license', not 'security'",
+		"assigned_policy.4.name: policy 'planned-policy' has type 'security', not 'operational_risk'",
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %q, got %q", expected, errs)
+	}
+}
+
+func TestCheckPolicyReferencesSkipsFailedRequests(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.failures["GET /xray/api/v2/policies/unreachable-policy"] = true
+
+	errs := checkPolicyReferences(context.Background(), restyClient, "", []objectReference{
+		{Path: "policies.0", Name: "unreachable-policy"},
+	})
+	if len(errs) > 0 {
+		t.Errorf("expected the failed request to skip the check, got %q", errs)
+	}
+}
+
+func TestCheckWatchReferences(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	defer resetPlannedObjects()
+	xray.watches["existing-watch"] = json.RawMessage(`{"general_data":{"name":"existing-watch"}}`)
+	recordPlannedObject("watch", "myproj", "planned-watch", "")
+
+	errs := checkWatchReferences(context.Background(), restyClient, "myproj", []objectReference{
+		{Path: "watches.0", Name: "existing-watch"},
+		{Path: "watches.1", Name: "planned-watch"},
+		{Path: "watches.2", Name: "missing-watch"},
+	})
+
+	expected := []string{"watches.2: watch 'missing-watch' doesn't exist in project 'myproj'"}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %q, got %q", expected, errs)
+	}
+}
+
+func TestIgnoreRuleReferencesDiff(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.policies["existing-policy"] = json.RawMessage(`{"name":"existing-policy","type":"security","rules":[]}`)
+
+	err := testPolicyDiff(resourceXrayIgnoreRule(), map[string]interface{}{
+		"notes":           "fake notes",
+		"vulnerabilities": []interface{}{"any"},
+		"policies":        []interface{}{"existing-policy", "missing-policy"},
+	}, restyClient)
+	if err == nil || !strings.Contains(err.Error(), "policy 'missing-policy' doesn't exist") || strings.Contains(err.Error(), "existing-policy'") {
+		t.Errorf("expected the missing policy to fail the plan, got: %v", err)
+	}
+}
+
+func TestCheckWebhookReferences(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.routes["GET /xray/api/v1/webhooks"] = jsonResponse(http.StatusOK, `[{"name":"slack","url":"https://hooks.example.com/slack"}]`)
+
+	rules := []interface{}{
+		map[string]interface{}{
+			"name": "rule",
+			"actions": []interface{}{
+				map[string]interface{}{"webhooks": []interface{}{"slack", "slak"}},
+			},
+		},
+	}
+
+	errs := checkWebhookReferences(context.Background(), restyClient, webhookReferences(rules))
+	expected := []string{"rule.0.actions.0.webhooks.1: webhook 'slak' doesn't exist"}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %q, got %q", expected, errs)
+	}
+}
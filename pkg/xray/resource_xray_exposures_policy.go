@@ -0,0 +1,79 @@
+package xray
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+)
+
+var exposuresCriteriaSchema = map[string]*schema.Schema{
+	"exposures": {
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "The exposures (secrets, IaC misconfigurations, services and applications misconfigurations) to examine when an artifact is scanned. Required for exposures policies.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"min_severity": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					Default:          "All Severities",
+					Description:      "The minimum severity of the exposures that will be impacted by the policy.",
+					ValidateDiagFunc: validator.StringInSlice(true, minSeverities...),
+					DiffSuppressFunc: suppressCaseDiff,
+				},
+				"secrets": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Default value is `true`. Trigger the rule for exposed secrets (e.g. tokens and keys).",
+				},
+				"applications": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Default value is `true`. Trigger the rule for applications misconfigurations.",
+				},
+				"services": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Default value is `true`. Trigger the rule for services misconfigurations.",
+				},
+				"iac": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Default value is `true`. Trigger the rule for Infrastructure as Code (IaC) misconfigurations.",
+				},
+			},
+		},
+	},
+}
+
+func resourceXrayExposuresPolicy() *schema.Resource {
+	return &schema.Resource{
+		// The resource is new, so there's no state of an older schema to upgrade, unlike the other policies
+		SchemaVersion: 0,
+		CreateContext: resourceXrayPolicyCreate,
+		ReadContext:   resourceXrayPolicyRead,
+		UpdateContext: resourceXrayPolicyUpdate,
+		DeleteContext: resourceXrayPolicyDelete,
+		Description: "Creates an Xray exposures policy using V2 of the underlying APIs. Please note: " +
+			"It's only compatible with Bearer token auth method (Identity and Access => Access Tokens)",
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: policyRulesDiff("exposures"),
+
+		Schema: util.MergeMaps(
+			getPolicySchema(exposuresCriteriaSchema, commonActionsSchema),
+			forceDetachOnDestroySchema,
+			ignoreExternalRulesSchema,
+			violationCountsSchema,
+			adoptExistingSchema,
+		),
+	}
+}
@@ -0,0 +1,91 @@
+package xray
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"github.com/jfrog/terraform-provider-shared/util"
+)
+
+func TestAccExposuresPolicy_assignedToWatch(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_exposures_policy")
+	watchFqrn := "xray_watch." + resourceName
+
+	testData := map[string]string{
+		"resource_name":      resourceName,
+		"policy_name":        fmt.Sprintf("terraform-exposures-policy-%d", test.RandomInt()),
+		"policy_description": "policy created by xray acceptance tests",
+		"rule_name":          fmt.Sprintf("test-exposures-rule-%d", test.RandomInt()),
+		"watch_name":         fmt.Sprintf("xray-watch-%d", test.RandomInt()),
+		"min_severity":       "High",
+		"iac":                "false",
+	}
+
+	template := `resource "xray_exposures_policy" "{{ .resource_name }}" {
+		name        = "{{ .policy_name }}"
+		description = "{{ .policy_description }}"
+		type        = "exposures"
+
+		rule {
+			name = "{{ .rule_name }}"
+			criteria {
+				exposures {
+					min_severity = "{{ .min_severity }}"
+					iac          = {{ .iac }}
+				}
+			}
+			actions {
+				fail_build = false
+				block_download {
+					unscanned = false
+					active    = true
+				}
+			}
+		}
+	}
+
+	resource "xray_watch" "{{ .resource_name }}" {
+		name   = "{{ .watch_name }}"
+		active = true
+
+		watch_resource {
+			type = "all-repos"
+		}
+
+		assigned_policy {
+			name = xray_exposures_policy.{{ .resource_name }}.name
+			type = "exposures"
+		}
+	}`
+
+	updatedTestData := util.MergeMaps(testData)
+	updatedTestData["min_severity"] = "Critical"
+	updatedTestData["iac"] = "true"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(watchFqrn, testCheckWatch),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "type", "exposures"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.exposures.0.min_severity", "High"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.exposures.0.secrets", "true"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.exposures.0.iac", "false"),
+					resource.TestCheckResourceAttr(watchFqrn, "assigned_policy.0.type", "exposures"),
+				),
+			},
+			{
+				Config: util.ExecuteTemplate(fqrn, template, updatedTestData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.exposures.0.min_severity", "Critical"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.exposures.0.iac", "true"),
+				),
+			},
+		},
+	})
+}
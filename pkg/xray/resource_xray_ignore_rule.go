@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -72,6 +73,12 @@ func resourceXrayIgnoreRule() *schema.Resource {
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringMatch(regexp.MustCompile(`^\d{4}-(0[1-9]|1[012])-(0[1-9]|[12][0-9]|3[0-1])$`), "Data must be in YYYY-MM-DD format")),
 				Description:      "The Ignore Rule will be active until the expiration date. At that date it will automatically get deleted.",
 			},
+			"adopt_existing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Description: "Default value is `false`. If an ignore rule with the same notes, expiration date and filters already exists in Xray, e.g. after a failed apply or a manual creation, " +
+					"take it under management instead of creating a duplicate.",
+			},
 			"author": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -126,6 +133,7 @@ func resourceXrayIgnoreRule() *schema.Resource {
 				Elem: &schema.Schema{
 					Type:             schema.TypeString,
 					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"any"}, true)),
+					DiffSuppressFunc: suppressCaseDiff,
 				},
 			},
 			"policies": {
@@ -412,6 +420,9 @@ func resourceXrayIgnoreRule() *schema.Resource {
 		}
 		operationalRisks := data.GetList("operational_risk")
 		if len(operationalRisks) > 0 {
+			for idx, risk := range operationalRisks {
+				operationalRisks[idx] = strings.ToLower(risk)
+			}
 			ignoreFilters.OperationalRisks = operationalRisks
 		}
 
@@ -461,6 +472,18 @@ func resourceXrayIgnoreRule() *schema.Resource {
 			return diag.FromErr(err)
 		}
 
+		if d.Get("adopt_existing").(bool) {
+			id, found, err := findIgnoreRule(m.(*resty.Client), ignoreRule)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if found {
+				tflog.Info(ctx, fmt.Sprintf("Adopting the existing Xray ignore rule (%s)", id))
+				d.SetId(id)
+				return resourceXrayIgnoreRuleRead(ctx, d, m)
+			}
+		}
+
 		req, err := getRestyRequest(m.(*resty.Client), ignoreRule.ProjectKey)
 		if err != nil {
 			return diag.FromErr(err)
@@ -472,11 +495,19 @@ func resourceXrayIgnoreRule() *schema.Resource {
 
 		response := IgnoreRuleCreateResponse{}
 
-		_, err = req.
+		resp, err := req.
 			SetBody(ignoreRule).
 			SetResult(&response).
 			Post("xray/api/v1/ignore_rules")
 		if err != nil {
+			// Xray may accept several ignore rules with the same content, so a matching rule only
+			// explains the failure when Xray reports a conflict
+			if resp == nil || resp.StatusCode() != http.StatusConflict {
+				return diag.FromErr(err)
+			}
+			if id, found, findErr := findIgnoreRule(m.(*resty.Client), ignoreRule); findErr == nil && found {
+				return alreadyExistsDiagnostics("xray_ignore_rule", fmt.Sprintf("Ignore rule %s", id), id)
+			}
 			return diag.FromErr(err)
 		}
 
@@ -517,16 +548,49 @@ func resourceXrayIgnoreRule() *schema.Resource {
 
 		return nil
 	}
+	// Only adopt_existing can be updated, which doesn't change the ignore rule
+	var resourceXrayIgnoreRuleUpdate = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		return resourceXrayIgnoreRuleRead(ctx, d, m)
+	}
+
 	return &schema.Resource{
 		CreateContext: resourceXrayIgnoreRuleCreate,
 		ReadContext:   resourceXrayIgnoreRuleRead,
+		UpdateContext: resourceXrayIgnoreRuleUpdate,
 		DeleteContext: resourceXrayIgnoreRuleDelete,
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		CustomizeDiff: ignoreRuleReferencesDiff,
+
 		Schema:      ignoreRuleSchema,
 		Description: "Provides an Xray ignore rule resource. See [Xray Ignore Rules](https://www.jfrog.com/confluence/display/JFROG/Ignore+Rules) and [REST API](https://www.jfrog.com/confluence/display/JFROG/Xray+REST+API#XrayRESTAPI-IGNORERULES) for more details. Notice: at least one of the 'vulnerabilities/cves/liceneses', 'component', and 'docker_layers/artifact/build/release_bundle' should not be empty. When selecting the ignore criteria, take note of the combinations you choose. Some combinations such as omitting everything is not allowed as it will ignore all future violations (in the watch or in the system).",
 	}
 }
+
+// ignoreRuleReferencesDiff checks that the policies and watches of a new ignore rule exist. The
+// ignore rules can't be updated, so the references of the existing ones aren't checked again.
+// The names which aren't known yet are skipped.
+func ignoreRuleReferencesDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	client, ok := meta.(*resty.Client)
+	if !ok || client == nil || diff.Id() != "" {
+		return nil
+	}
+
+	rawConfig := diff.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil
+	}
+	config := rawConfigValue(rawConfig).(map[string]interface{})
+	projectKey, _ := config["project_key"].(string)
+
+	errs := checkPolicyReferences(ctx, client, projectKey, stringReferences("policies", config["policies"]))
+	errs = append(errs, checkWatchReferences(ctx, client, projectKey, stringReferences("watches", config["watches"]))...)
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid references:\n%s", strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
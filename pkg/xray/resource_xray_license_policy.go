@@ -6,55 +6,65 @@ import (
 	"github.com/jfrog/terraform-provider-shared/validator"
 )
 
-func resourceXrayLicensePolicyV2() *schema.Resource {
-	var criteriaSchema = map[string]*schema.Schema{
-		"banned_licenses": {
-			Type:        schema.TypeSet,
-			Optional:    true,
-			Description: "A list of OSS license names that may not be attached to a component.",
-			Elem: &schema.Schema{
-				Type:             schema.TypeString,
-				ValidateDiagFunc: validator.LicenseType,
-			},
-		},
-		"allowed_licenses": {
-			Type:        schema.TypeSet,
-			Optional:    true,
-			Description: "A list of OSS license names that may be attached to a component.",
-			Elem: &schema.Schema{
-				Type:             schema.TypeString,
-				ValidateDiagFunc: validator.LicenseType,
-			},
+var licenseCriteriaSchema = map[string]*schema.Schema{
+	"banned_licenses": {
+		Type:     schema.TypeSet,
+		Optional: true,
+		Description: "A list of OSS license names that may not be attached to a component. " +
+			"The names are validated against the license catalog embedded in the provider (see the `xray_license_group` data source): unknown names are rejected with the closest valid name. The deprecated SPDX identifiers (e.g. `GPL-3.0`) are accepted, as Xray still reports them for some components: list them along with their replacements (e.g. `GPL-3.0-only`).",
+		Elem: &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateDiagFunc: validateLicenseName,
 		},
-		"allow_unknown": {
-			Type:        schema.TypeBool,
-			Optional:    true,
-			Default:     true,
-			Description: "A violation will be generated for artifacts with unknown licenses (`true` or `false`).",
+	},
+	"allowed_licenses": {
+		Type:     schema.TypeSet,
+		Optional: true,
+		Description: "A list of OSS license names that may be attached to a component. " +
+			"The names are validated against the license catalog embedded in the provider (see the `xray_license_group` data source): unknown names are rejected with the closest valid name. The deprecated SPDX identifiers (e.g. `GPL-3.0`) are accepted, as Xray still reports them for some components: list them along with their replacements (e.g. `GPL-3.0-only`).",
+		Elem: &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateDiagFunc: validateLicenseName,
 		},
-		"multi_license_permissive": {
-			Type:        schema.TypeBool,
-			Optional:    true,
-			Default:     false,
-			Description: "Do not generate a violation if at least one license is valid in cases whereby multiple licenses were detected on the component",
-		},
-	}
+	},
+	"allow_unknown": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "A violation will be generated for artifacts with unknown licenses (`true` or `false`).",
+	},
+	"multi_license_permissive": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Do not generate a violation if at least one license is valid in cases whereby multiple licenses were detected on the component",
+	},
+}
 
-	var actionsSchema = util.MergeMaps(
-		commonActionsSchema,
-		map[string]*schema.Schema{
-			"custom_severity": {
-				Type:             schema.TypeString,
-				Optional:         true,
-				Default:          "High",
-				Description:      "The severity of violation to be triggered if the `criteria` are met.",
-				ValidateDiagFunc: validator.StringInSlice(true, "Critical", "High", "Medium", "Low"),
-			},
+var licenseActionsSchema = util.MergeMaps(
+	commonActionsSchema,
+	map[string]*schema.Schema{
+		"custom_severity": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Default:          "High",
+			Description:      "The severity of violation to be triggered if the `criteria` are met.",
+			ValidateDiagFunc: validator.StringInSlice(true, customSeverities...),
+			DiffSuppressFunc: suppressCaseDiff,
 		},
-	)
+	},
+)
 
+func resourceXrayLicensePolicyV2() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceXrayPolicyV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: policyStateUpgradeV0,
+				Version: 0,
+			},
+		},
 		CreateContext: resourceXrayPolicyCreate,
 		ReadContext:   resourceXrayPolicyRead,
 		UpdateContext: resourceXrayPolicyUpdate,
@@ -66,6 +76,14 @@ func resourceXrayLicensePolicyV2() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
-		Schema: getPolicySchema(criteriaSchema, actionsSchema),
+		CustomizeDiff: policyRulesDiff("license"),
+
+		Schema: util.MergeMaps(
+			getPolicySchema(licenseCriteriaSchema, licenseActionsSchema),
+			forceDetachOnDestroySchema,
+			ignoreExternalRulesSchema,
+			violationCountsSchema,
+			adoptExistingSchema,
+		),
 	}
 }
@@ -35,9 +35,8 @@ var testDataLicense = map[string]string{
 }
 
 // License policy criteria are different from the security policy criteria
-// Test will try to post a new license policy with incorrect body of security policy
-// with specified cvss_range. The function unpackLicenseCriteria will ignore all the
-// fields except of "allow_unknown", "banned_licenses" and "allowed_licenses" if the Policy type is "license"
+// Test will try to create a security policy with the type of "license" and a cvss_range criteria.
+// The policy type must match the resource, which is verified at plan time.
 func TestAccLicensePolicy_badLicenseCriteria(t *testing.T) {
 	_, fqrn, resourceName := test.MkNames("policy-", "xray_license_policy")
 	policyName := fmt.Sprintf("terraform-license-policy-1-%d", test.RandomInt())
@@ -52,7 +51,7 @@ func TestAccLicensePolicy_badLicenseCriteria(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config:      testAccXrayLicensePolicy_badLicense(resourceName, policyName, policyDesc, ruleName, rangeTo),
-				ExpectError: regexp.MustCompile("Found Invalid Policy"),
+				ExpectError: regexp.MustCompile("attribute 'type' must be 'security' for this resource, got 'license'"),
 			},
 		},
 	})
@@ -76,7 +75,7 @@ func TestAccLicensePolicy_badGracePeriod(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config:      util.ExecuteTemplate(fqrn, licensePolicyTemplate, testData),
-				ExpectError: regexp.MustCompile("Found Invalid Policy"),
+				ExpectError: regexp.MustCompile("attribute 'build_failure_grace_period_in_days' can only be set when 'fail_build' is enabled"),
 			},
 		},
 	})
@@ -0,0 +1,346 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+	"golang.org/x/exp/slices"
+)
+
+// GenericPolicy is a policy with the rules kept as raw JSON, so criteria and actions not modeled by
+// the typed policy resources are sent and read as is.
+type GenericPolicy struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	ProjectKey  string          `json:"-"`
+	Author      string          `json:"author,omitempty"` // Omitempty is used because the field is computed
+	Description string          `json:"description"`
+	Rules       json.RawMessage `json:"rules"`
+	Created     string          `json:"created,omitempty"`  // Omitempty is used because the field is computed
+	Modified    string          `json:"modified,omitempty"` // Omitempty is used because the field is computed
+}
+
+func resourceXrayPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceXrayGenericPolicyCreate,
+		ReadContext:   resourceXrayGenericPolicyRead,
+		UpdateContext: resourceXrayGenericPolicyUpdate,
+		DeleteContext: resourceXrayPolicyDelete,
+		Description: "Creates an Xray policy of any type from the rules in the JSON format of the Xray API. " +
+			"Use it for the criteria and actions that are not supported yet by `xray_security_policy`, `xray_license_policy`, `xray_operational_risk_policy` and `xray_exposures_policy`.",
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: genericPolicyDiff,
+
+		Schema: util.MergeMaps(
+			getProjectKeySchema(true, "Changing the project key destroys the policy in the old project and creates it in the new one."),
+			forceDetachOnDestroySchema,
+			violationCountsSchema,
+			adoptExistingSchema,
+			map[string]*schema.Schema{
+				"name": {
+					Type:             schema.TypeString,
+					Required:         true,
+					ForceNew:         true,
+					Description:      "Name of the policy (must be unique)",
+					ValidateDiagFunc: validator.StringIsNotEmpty,
+				},
+				"description": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "More verbose description of the policy",
+				},
+				"type": {
+					Type:             schema.TypeString,
+					Required:         true,
+					Description:      "Type of the policy: `security`, `license`, `operational_risk` or `exposures`.",
+					ValidateDiagFunc: validator.StringInSlice(true, policyTypes...),
+					DiffSuppressFunc: suppressCaseDiff,
+				},
+				"rules_json": {
+					Type:             schema.TypeString,
+					Required:         true,
+					Description:      "The rules of the policy as a JSON array, in the format of the `rules` of the Xray policies API (as exported from the Xray UI or API). The JSON is sent as is. It's compared semantically: the order of the keys and of the rules, the case of the enum values (e.g. `min_severity`) and the attributes with a default value added by Xray are ignored.",
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
+					DiffSuppressFunc: suppressEquivalentJSONDiff,
+				},
+				"author": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "User, who created the policy",
+				},
+				"created": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Creation timestamp",
+				},
+				"modified": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Modification timestamp",
+				},
+			},
+		),
+	}
+}
+
+func unpackGenericPolicy(d *schema.ResourceData) *GenericPolicy {
+	return &GenericPolicy{
+		Name:        d.Get("name").(string),
+		Type:        canonicalEnumValue(d.Get("type").(string), policyTypes),
+		ProjectKey:  d.Get("project_key").(string),
+		Description: d.Get("description").(string),
+		Rules:       json.RawMessage(d.Get("rules_json").(string)),
+	}
+}
+
+func packGenericPolicy(policy GenericPolicy, d *schema.ResourceData) diag.Diagnostics {
+	if err := d.Set("name", policy.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("type", canonicalEnumValue(policy.Type, policyTypes)); err != nil {
+		return diag.FromErr(err)
+	}
+	if len(policy.Description) > 0 {
+		if err := d.Set("description", policy.Description); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err := d.Set("author", policy.Author); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("created", policy.Created); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("modified", policy.Modified); err != nil {
+		return diag.FromErr(err)
+	}
+
+	rulesJSON, err := readRulesJSON(d.Get("rules_json").(string), policy.Rules)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("rules_json", rulesJSON); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// readRulesJSON returns the rules JSON to store in the state. The current JSON is kept if the rules
+// read from Xray match it, ignoring the order of the keys and the attributes added by Xray.
+// Otherwise, the rules read from Xray are returned, normalized.
+func readRulesJSON(current string, remote json.RawMessage) (string, error) {
+	var remoteRules interface{}
+	if err := json.Unmarshal(remote, &remoteRules); err != nil {
+		return "", fmt.Errorf("failed to parse the policy rules returned by Xray: %s", err)
+	}
+
+	var currentRules interface{}
+	if err := json.Unmarshal([]byte(current), &currentRules); err == nil && jsonSubset(currentRules, remoteRules) {
+		return current, nil
+	}
+
+	normalized, err := json.Marshal(remoteRules)
+	if err != nil {
+		return "", err
+	}
+
+	return string(normalized), nil
+}
+
+// caseInsensitiveRuleKeys are the keys of the enum values of the rules, which Xray returns in its own case
+var caseInsensitiveRuleKeys = []string{"min_severity", "custom_severity", "risk", "op_risk_min_risk", "package_type", "type"}
+
+// jsonSubset reports whether expected is contained in actual: objects of actual may have additional keys,
+// arrays must have the same length and values must be equal. The strings of the enum keys are compared
+// ignoring the case. The arrays of named objects, such as the rules, are matched by name, as Xray may
+// return them in another order.
+func jsonSubset(expected, actual interface{}) bool {
+	return jsonSubsetOfKey("", expected, actual)
+}
+
+func jsonSubsetOfKey(key string, expected, actual interface{}) bool {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range expectedValue {
+			if !jsonSubsetOfKey(key, value, actualValue[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || len(actualValue) != len(expectedValue) {
+			return false
+		}
+		if actualByName, ok := jsonObjectsByName(actualValue); ok {
+			if expectedByName, ok := jsonObjectsByName(expectedValue); ok && len(expectedByName) == len(expectedValue) {
+				for name, value := range expectedByName {
+					if !jsonSubsetOfKey(key, value, actualByName[name]) {
+						return false
+					}
+				}
+				return true
+			}
+		}
+		for idx := range expectedValue {
+			if !jsonSubsetOfKey(key, expectedValue[idx], actualValue[idx]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		actualValue, ok := actual.(string)
+		if ok && slices.Contains(caseInsensitiveRuleKeys, key) {
+			return strings.EqualFold(expectedValue, actualValue)
+		}
+		return ok && expectedValue == actualValue
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
+
+// jsonObjectsByName indexes the objects of the array by their name. It returns false if an element
+// isn't an object with a name.
+func jsonObjectsByName(values []interface{}) (map[string]interface{}, bool) {
+	byName := map[string]interface{}{}
+	for _, value := range values {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := object["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		byName[name] = object
+	}
+
+	return byName, true
+}
+
+// suppressEquivalentJSONDiff ignores differences in formatting and key order between two JSON documents
+var suppressEquivalentJSONDiff = func(_, old, new string, _ *schema.ResourceData) bool {
+	return equivalentJSON(old, new)
+}
+
+// equivalentJSON reports whether two JSON documents are equal, ignoring their formatting and key order
+func equivalentJSON(a, b string) bool {
+	var aValue, bValue interface{}
+	if err := json.Unmarshal([]byte(a), &aValue); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &bValue); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// genericPolicyDiff records the policy of the plan, for the references of the watches and ignore rules
+func genericPolicyDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	recordPlannedObject("policy", diff.Get("project_key").(string), diff.Get("name").(string), canonicalEnumValue(diff.Get("type").(string), policyTypes))
+	return nil
+}
+
+func resourceXrayGenericPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policy := unpackGenericPolicy(d)
+
+	req, err := getRestyRequest(m.(*resty.Client), policy.ProjectKey)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Get("adopt_existing").(bool) {
+		exists, err := policyExists(m.(*resty.Client), policy.ProjectKey, policy.Name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if exists {
+			tflog.Info(ctx, fmt.Sprintf("Adopting the existing Xray policy (%s)", policy.Name))
+			d.SetId(policy.Name)
+			return resourceXrayGenericPolicyUpdate(ctx, d, m)
+		}
+	}
+
+	_, err = req.SetBody(policy).Post("xray/api/v2/policies")
+	if err != nil {
+		if exists, existsErr := policyExists(m.(*resty.Client), policy.ProjectKey, policy.Name); existsErr == nil && exists {
+			return alreadyExistsDiagnostics("xray_policy", fmt.Sprintf("Policy %s", policy.Name), policy.Name)
+		}
+		return diag.FromErr(err)
+	}
+
+	d.SetId(policy.Name)
+	return resourceXrayGenericPolicyRead(ctx, d, m)
+}
+
+func resourceXrayGenericPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policy := GenericPolicy{}
+
+	req, err := getRestyRequest(m.(*resty.Client), d.Get("project_key").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resp, err := req.
+		SetResult(&policy).
+		SetPathParams(map[string]string{
+			"name": d.Id(),
+		}).
+		Get("xray/api/v2/policies/{name}")
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			tflog.Warn(ctx, fmt.Sprintf("Xray policy (%s) not found, removing from state", d.Id()))
+			d.SetId("")
+		}
+		return diag.FromErr(err)
+	}
+	diags := packGenericPolicy(policy, d)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, packViolationCounts(d, m.(*resty.Client), ViolationsFilters{PolicyName: d.Id()})...)
+}
+
+func resourceXrayGenericPolicyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policy := unpackGenericPolicy(d)
+
+	req, err := getRestyRequest(m.(*resty.Client), policy.ProjectKey)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, err = req.
+		SetBody(policy).
+		SetPathParams(map[string]string{
+			"name": d.Id(),
+		}).
+		Put("xray/api/v2/policies/{name}")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(policy.Name)
+	return resourceXrayGenericPolicyRead(ctx, d, m)
+}
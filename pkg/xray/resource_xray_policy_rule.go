@@ -0,0 +1,340 @@
+package xray
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+	"golang.org/x/exp/slices"
+)
+
+func resourceXrayPolicyRule() *schema.Resource {
+	ruleSchema := getPolicySchema(policyCriteriaSchema, licenseActionsSchema)["rule"].Elem.(*schema.Resource).Schema
+	ruleSchema["name"].ForceNew = true
+	ruleSchema["name"].Description = "Name of the rule (must be unique in the policy)"
+	// The priority of a rule added to a policy is computed by Xray rather than from a position in a list
+	ruleSchema["priority"].Computed = true
+	ruleSchema["priority"].DiffSuppressFunc = nil
+	ruleSchema["priority"].Description = "Integer describing the rule priority. Must be at least 1 and not used by another rule of the policy. " +
+		"When omitted, the rule is added after the existing rules of the policy."
+
+	return &schema.Resource{
+		CreateContext: resourceXrayPolicyRuleCreate,
+		ReadContext:   resourceXrayPolicyRuleRead,
+		UpdateContext: resourceXrayPolicyRuleUpdate,
+		DeleteContext: resourceXrayPolicyRuleDelete,
+		Description: "Manages a single rule of an existing Xray policy, so the rules of a policy can be managed from several configurations. " +
+			"The criteria and actions supported depend on the type of the policy. " +
+			"Set `ignore_external_rules` on the policy resource, so it doesn't remove the rules managed by this resource.",
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceXrayPolicyRuleImport,
+		},
+
+		Schema: util.MergeMaps(
+			getProjectKeySchema(true, "The policy is read from the project scope. Changing the project key moves the rule to the policy of the new project."),
+			ruleSchema,
+			map[string]*schema.Schema{
+				"policy_name": {
+					Type:             schema.TypeString,
+					Required:         true,
+					ForceNew:         true,
+					Description:      "Name of the policy the rule is added to. The policy must exist.",
+					ValidateDiagFunc: validator.StringIsNotEmpty,
+				},
+			},
+		),
+	}
+}
+
+func policyRuleId(policyName, ruleName string) string {
+	return fmt.Sprintf("%s:%s", policyName, ruleName)
+}
+
+// resourceXrayPolicyRuleImport imports a rule from an ID in the format `policy_name:rule_name`,
+// or `policy_name:rule_name:project_key` for the policies of a project
+func resourceXrayPolicyRuleImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) < 2 || len(parts) > 3 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected policy_name:rule_name or policy_name:rule_name:project_key", d.Id())
+	}
+
+	if err := d.Set("policy_name", parts[0]); err != nil {
+		return nil, err
+	}
+	if err := d.Set("name", parts[1]); err != nil {
+		return nil, err
+	}
+	if len(parts) == 3 {
+		if err := d.Set("project_key", parts[2]); err != nil {
+			return nil, err
+		}
+	}
+	d.SetId(policyRuleId(parts[0], parts[1]))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// validatePolicyRule validates the rule, read from the raw configuration, against the type of the policy.
+// The type is only known once the policy is read, so this is done on apply.
+func validatePolicyRule(d *schema.ResourceData, policyType string) error {
+	config, _ := rawConfigValue(d.GetRawConfig()).(map[string]interface{})
+	if config == nil {
+		return nil
+	}
+
+	rules := []interface{}{config}
+	errs := append(validateRules(rules, policyType), validateRuleAttributesForType(rules, policyType)...)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for idx := range errs {
+		errs[idx] = strings.TrimPrefix(errs[idx], "rule.0.")
+	}
+	return fmt.Errorf("invalid rule for '%s' policy %s:\n%s", policyType, d.Get("policy_name").(string), strings.Join(errs, "\n"))
+}
+
+// unpackPolicyRule returns the rule with its priority. When the priority is omitted, the current priority
+// of the rule is kept, or the rule is added after the other rules of the policy.
+func unpackPolicyRule(d *schema.ResourceData, policyType string, rules []PolicyRule) (*PolicyRule, error) {
+	rule := PolicyRule{
+		Name:     d.Get("name").(string),
+		Priority: d.Get("priority").(int),
+	}
+
+	criteria, err := unpackCriteria(d.Get("criteria").(*schema.Set), policyType)
+	if err != nil {
+		return nil, err
+	}
+	rule.Criteria = criteria
+	if actions := d.Get("actions").(*schema.Set); actions.Len() > 0 {
+		rule.Actions = unpackActions(actions)
+	}
+	// custom_severity is only sent for license policies, see packActions
+	if policyType != "license" {
+		rule.Actions.CustomSeverity = ""
+	}
+
+	maxPriority := 0
+	for _, other := range rules {
+		if other.Name == rule.Name {
+			if rule.Priority == 0 {
+				rule.Priority = other.Priority
+			}
+			continue
+		}
+		if other.Priority == rule.Priority {
+			return nil, fmt.Errorf("priority %d is already used by rule %s of policy %s", rule.Priority, other.Name, d.Get("policy_name").(string))
+		}
+		if other.Priority > maxPriority {
+			maxPriority = other.Priority
+		}
+	}
+	if rule.Priority == 0 {
+		rule.Priority = maxPriority + 1
+	}
+
+	return &rule, nil
+}
+
+func packPolicyRule(rule PolicyRule, policyType string, d *schema.ResourceData) diag.Diagnostics {
+	packed := packRules([]PolicyRule{rule}, policyType)[0].(map[string]interface{})
+
+	// The rule schema has the attributes of all the policy types, the attributes of the other
+	// types are set to their default value to match the configuration
+	criteria := packed["criteria"].([]interface{})[0].(map[string]interface{})
+	actions := packed["actions"].([]interface{})[0].(map[string]interface{})
+	setSchemaDefaults(criteria, policyCriteriaSchema)
+	setSchemaDefaults(actions, licenseActionsSchema)
+
+	if err := d.Set("priority", rule.Priority); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("criteria", packed["criteria"]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("actions", packed["actions"]); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// setSchemaDefaults sets the attributes missing from m to their default value in the schema
+func setSchemaDefaults(m map[string]interface{}, s map[string]*schema.Schema) {
+	for key, attr := range s {
+		if _, ok := m[key]; !ok && attr.Default != nil {
+			m[key] = attr.Default
+		}
+	}
+}
+
+// updatePolicyRules replaces the rule named ruleName in the policy by rule, or removes it when rule is nil.
+// The other rules of the policy are sent back to Xray unchanged.
+func updatePolicyRules(client *resty.Client, projectKey string, policy *PolicyJSON, rules []PolicyRule, ruleName string, rule *PolicyRule) error {
+	var managed []PolicyRule
+	if rule != nil {
+		managed = append(managed, *rule)
+		if err := checkPolicyFeaturesSupport(&Policy{Rules: &managed}, client); err != nil {
+			return err
+		}
+	}
+
+	rawRules, err := replaceRules(policy.Rules, rules, managed, func(name string) bool { return name == ruleName })
+	if err != nil {
+		return err
+	}
+	policy.Rules = rawRules
+	// Computed fields are not sent back
+	policy.Created = ""
+	policy.Modified = ""
+
+	req, err := getRestyRequest(client, projectKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = req.
+		SetBody(policy).
+		SetPathParams(map[string]string{
+			"name": policy.Name,
+		}).
+		Put("xray/api/v2/policies/{name}")
+
+	return err
+}
+
+func resourceXrayPolicyRuleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*resty.Client)
+	projectKey := d.Get("project_key").(string)
+	policyName := d.Get("policy_name").(string)
+	ruleName := d.Get("name").(string)
+
+	policyLocks.Lock(policyLockKey(projectKey, policyName))
+	defer policyLocks.Unlock(policyLockKey(projectKey, policyName))
+
+	policy, rules, _, err := getPolicyJSON(client, projectKey, policyName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	policyType := canonicalEnumValue(policy.Type, policyTypes)
+
+	for _, rule := range rules {
+		if rule.Name == ruleName {
+			return diag.Errorf("rule %s already exists in policy %s, import it with the ID %s", ruleName, policyName, policyRuleId(policyName, ruleName))
+		}
+	}
+
+	if err := validatePolicyRule(d, policyType); err != nil {
+		return diag.FromErr(err)
+	}
+
+	rule, err := unpackPolicyRule(d, policyType, rules)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := updatePolicyRules(client, projectKey, policy, rules, ruleName, rule); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(policyRuleId(policyName, ruleName))
+	return packPolicyRule(*rule, policyType, d)
+}
+
+func resourceXrayPolicyRuleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	policyName := d.Get("policy_name").(string)
+	ruleName := d.Get("name").(string)
+
+	policy, resp, err := getPolicy(m.(*resty.Client), d.Get("project_key").(string), policyName)
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			tflog.Warn(ctx, fmt.Sprintf("Xray policy (%s) not found, removing rule (%s) from state", policyName, ruleName))
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	for _, rule := range *policy.Rules {
+		if rule.Name == ruleName {
+			return packPolicyRule(rule, canonicalEnumValue(policy.Type, policyTypes), d)
+		}
+	}
+
+	tflog.Warn(ctx, fmt.Sprintf("Rule (%s) not found in Xray policy (%s), removing from state", ruleName, policyName))
+	d.SetId("")
+	return nil
+}
+
+func resourceXrayPolicyRuleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*resty.Client)
+	projectKey := d.Get("project_key").(string)
+	policyName := d.Get("policy_name").(string)
+	ruleName := d.Get("name").(string)
+
+	policyLocks.Lock(policyLockKey(projectKey, policyName))
+	defer policyLocks.Unlock(policyLockKey(projectKey, policyName))
+
+	policy, rules, _, err := getPolicyJSON(client, projectKey, policyName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	policyType := canonicalEnumValue(policy.Type, policyTypes)
+
+	if err := validatePolicyRule(d, policyType); err != nil {
+		return diag.FromErr(err)
+	}
+
+	rule, err := unpackPolicyRule(d, policyType, rules)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The rule is added again if it was removed outside of Terraform
+	if err := updatePolicyRules(client, projectKey, policy, rules, ruleName, rule); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return packPolicyRule(*rule, policyType, d)
+}
+
+func resourceXrayPolicyRuleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*resty.Client)
+	projectKey := d.Get("project_key").(string)
+	policyName := d.Get("policy_name").(string)
+	ruleName := d.Get("name").(string)
+
+	policyLocks.Lock(policyLockKey(projectKey, policyName))
+	defer policyLocks.Unlock(policyLockKey(projectKey, policyName))
+
+	policy, rules, resp, err := getPolicyJSON(client, projectKey, policyName)
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			tflog.Warn(ctx, fmt.Sprintf("Xray policy (%s) not found, rule (%s) is already deleted", policyName, ruleName))
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	if slices.IndexFunc(rules, func(rule PolicyRule) bool { return rule.Name == ruleName }) < 0 {
+		return nil
+	}
+	if len(rules) == 1 {
+		return diag.Errorf("rule %s is the last rule of policy %s and a policy requires at least one rule, destroy the policy instead", ruleName, policyName)
+	}
+
+	if err := updatePolicyRules(client, projectKey, policy, rules, ruleName, nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,380 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"github.com/jfrog/terraform-provider-shared/util"
+)
+
+// addTestPolicy stores the policy in the test server, and returns a function reading it back, to test the
+// read-modify-write of its rules
+func addTestPolicy(t *testing.T, xray *testXrayServer, policy Policy) func() Policy {
+	body, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xray.policies[policy.Name] = body
+
+	return func() Policy {
+		xray.lock.Lock()
+		defer xray.lock.Unlock()
+
+		stored := Policy{}
+		if err := json.Unmarshal(xray.policies[policy.Name], &stored); err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+}
+
+func testPolicyRuleData(t *testing.T, ruleName string) *schema.ResourceData {
+	return schema.TestResourceDataRaw(t, resourceXrayPolicyRule().Schema, map[string]interface{}{
+		"policy_name": "test-policy",
+		"name":        ruleName,
+		"criteria": []interface{}{
+			map[string]interface{}{"min_severity": "Critical"},
+		},
+		"actions": []interface{}{
+			map[string]interface{}{
+				"block_download": []interface{}{
+					map[string]interface{}{"unscanned": false, "active": true},
+				},
+			},
+		},
+	})
+}
+
+func TestPolicyRuleCreateAndDelete(t *testing.T) {
+	policy := Policy{
+		Name: "test-policy",
+		Type: "security",
+		Rules: &[]PolicyRule{
+			{Name: "baseline", Priority: 1, Criteria: &PolicyRuleCriteria{MinimumSeverity: "High"}},
+		},
+	}
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	storedPolicy := addTestPolicy(t, xray, policy)
+
+	// Rules of several teams are added concurrently to the same policy
+	var wg sync.WaitGroup
+	for _, ruleName := range []string{"team-a", "team-b", "team-c"} {
+		wg.Add(1)
+		go func(ruleName string) {
+			defer wg.Done()
+			d := testPolicyRuleData(t, ruleName)
+			if diags := resourceXrayPolicyRuleCreate(context.Background(), d, restyClient); diags.HasError() {
+				t.Errorf("unexpected error: %v", diags)
+			}
+			if d.Id() != "test-policy:"+ruleName {
+				t.Errorf("unexpected ID %s", d.Id())
+			}
+		}(ruleName)
+	}
+	wg.Wait()
+
+	policy = storedPolicy()
+	priorities := map[int]string{}
+	for _, rule := range *policy.Rules {
+		if other, ok := priorities[rule.Priority]; ok {
+			t.Errorf("rules %s and %s have the same priority %d", other, rule.Name, rule.Priority)
+		}
+		priorities[rule.Priority] = rule.Name
+	}
+	if len(*policy.Rules) != 4 || (*policy.Rules)[0].Name != "baseline" {
+		t.Fatalf("expected the rules to be added after the baseline rule, got %v", *policy.Rules)
+	}
+
+	d := testPolicyRuleData(t, "team-b")
+	if diags := resourceXrayPolicyRuleCreate(context.Background(), d, restyClient); !diags.HasError() || !strings.Contains(diags[0].Summary, "import it with the ID test-policy:team-b") {
+		t.Errorf("expected an error for the existing rule, got: %v", diags)
+	}
+
+	if diags := resourceXrayPolicyRuleDelete(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	policy = storedPolicy()
+	for _, rule := range *policy.Rules {
+		if rule.Name == "team-b" {
+			t.Errorf("expected rule team-b to be deleted")
+		}
+	}
+	if len(*policy.Rules) != 3 {
+		t.Errorf("expected the other rules to be kept, got %v", *policy.Rules)
+	}
+}
+
+func TestPolicyRuleDeleteLastRule(t *testing.T) {
+	policy := Policy{
+		Name:  "test-policy",
+		Type:  "security",
+		Rules: &[]PolicyRule{{Name: "team-a", Priority: 1}},
+	}
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	addTestPolicy(t, xray, policy)
+
+	diags := resourceXrayPolicyRuleDelete(context.Background(), testPolicyRuleData(t, "team-a"), restyClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "is the last rule of policy test-policy") {
+		t.Errorf("expected an error for the last rule, got: %v", diags)
+	}
+}
+
+func TestPolicyRulePriorityInUse(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceXrayPolicyRule().Schema, map[string]interface{}{
+		"policy_name": "test-policy",
+		"name":        "team-a",
+		"priority":    1,
+		"criteria": []interface{}{
+			map[string]interface{}{"min_severity": "Critical"},
+		},
+	})
+
+	_, err := unpackPolicyRule(d, "security", []PolicyRule{{Name: "baseline", Priority: 1}})
+	if err == nil || err.Error() != "priority 1 is already used by rule baseline of policy test-policy" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPolicyRuleImport(t *testing.T) {
+	testCases := []struct {
+		id                 string
+		expectedPolicyName string
+		expectedProjectKey string
+		expectedError      bool
+	}{
+		{"test-policy:team-a", "test-policy", "", false},
+		{"test-policy:team-a:myproj", "test-policy", "myproj", false},
+		{"test-policy", "", "", true},
+		{":team-a", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			d := resourceXrayPolicyRule().TestResourceData()
+			d.SetId(tc.id)
+
+			_, err := resourceXrayPolicyRuleImport(context.Background(), d, nil)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if d.Get("policy_name") != tc.expectedPolicyName || d.Get("name") != "team-a" || d.Get("project_key") != tc.expectedProjectKey {
+				t.Errorf("unexpected attributes: %s, %s, %s", d.Get("policy_name"), d.Get("name"), d.Get("project_key"))
+			}
+			if d.Id() != "test-policy:team-a" {
+				t.Errorf("unexpected ID %s", d.Id())
+			}
+		})
+	}
+}
+
+func TestAccPolicyRule_externalRules(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	ruleFqrn := "xray_policy_rule." + resourceName
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-18-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-18-%d", test.RandomInt())
+	testData["team_rule_name"] = fmt.Sprintf("test-team-rule-18-%d", test.RandomInt())
+
+	template := `resource "xray_security_policy" "{{ .resource_name }}" {
+		name                  = "{{ .policy_name }}"
+		description           = "{{ .policy_description }}"
+		type                  = "security"
+		ignore_external_rules = true
+
+		rule {
+			name = "{{ .rule_name }}"
+			criteria {
+				min_severity = "{{ .min_severity }}"
+			}
+			actions {
+				block_download {
+					unscanned = {{ .block_unscanned }}
+					active    = {{ .block_active }}
+				}
+			}
+		}
+	}
+
+	resource "xray_policy_rule" "{{ .resource_name }}" {
+		policy_name = xray_security_policy.{{ .resource_name }}.name
+		name        = "{{ .team_rule_name }}"
+
+		criteria {
+			min_severity = "Critical"
+		}
+		actions {
+			fail_build = false
+			block_download {
+				unscanned = false
+				active    = true
+			}
+		}
+	}`
+
+	updatedTestData := util.MergeMaps(testData)
+	updatedTestData["min_severity"] = "Medium"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.#", "1"),
+					resource.TestCheckResourceAttr(ruleFqrn, "priority", "2"),
+					resource.TestCheckResourceAttr(ruleFqrn, "criteria.0.min_severity", "Critical"),
+				),
+			},
+			{
+				Config: util.ExecuteTemplate(fqrn, template, updatedTestData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.#", "1"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.min_severity", "Medium"),
+					resource.TestCheckResourceAttr(ruleFqrn, "criteria.0.min_severity", "Critical"),
+				),
+			},
+			{
+				ResourceName:      ruleFqrn,
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s:%s", testData["policy_name"], testData["team_rule_name"]),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestPolicyUpdateIgnoreExternalRules(t *testing.T) {
+	policy := Policy{
+		Name: "test-policy",
+		Type: "security",
+		Rules: &[]PolicyRule{
+			{Name: "baseline", Priority: 1, Criteria: &PolicyRuleCriteria{MinimumSeverity: "High"}},
+			{Name: "team-a", Priority: 2, Criteria: &PolicyRuleCriteria{MinimumSeverity: "Critical"}},
+		},
+	}
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	storedPolicy := addTestPolicy(t, xray, policy)
+
+	d := schema.TestResourceDataRaw(t, resourceXraySecurityPolicyV2().Schema, map[string]interface{}{
+		"name":                  "test-policy",
+		"type":                  "security",
+		"ignore_external_rules": true,
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name":     "baseline",
+				"criteria": []interface{}{map[string]interface{}{"min_severity": "Medium"}},
+				"actions": []interface{}{
+					map[string]interface{}{
+						"block_download": []interface{}{
+							map[string]interface{}{"unscanned": false, "active": true},
+						},
+					},
+				},
+			},
+		},
+	})
+	d.SetId("test-policy")
+
+	if diags := resourceXrayPolicyUpdate(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	policy = storedPolicy()
+	if len(*policy.Rules) != 2 || (*policy.Rules)[0].Criteria.MinimumSeverity != "Medium" || (*policy.Rules)[1].Name != "team-a" {
+		t.Errorf("expected the external rule to be kept, got %v", *policy.Rules)
+	}
+	if rules := d.Get("rule").([]interface{}); len(rules) != 1 {
+		t.Errorf("expected the external rule to be ignored in the state, got %v", rules)
+	}
+}
+
+func TestPolicyUpdateIgnoreExternalRulesKeepsJSON(t *testing.T) {
+	testCases := []struct {
+		name             string
+		externalPriority int
+		expectedError    string
+	}{
+		{"external rule after the managed rules", 3, ""},
+		{"external rule with the priority of a managed rule", 2, "priority 2 of rule second is already used by rule team-a, which isn't managed by this resource"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xray, restyClient, closeServer := newTestXrayServer(t)
+			defer closeServer()
+			externalRule := fmt.Sprintf(`{"name":"team-a","priority":%d,"criteria":{"min_severity":"Critical","unknown_criteria":true},"actions":{"fail_build":true}}`, tc.externalPriority)
+			xray.policies["test-policy"] = json.RawMessage(`{"name":"test-policy","type":"security","rules":[` +
+				`{"name":"baseline","priority":1,"criteria":{"min_severity":"High"}},` +
+				externalRule + `]}`)
+
+			rule := func(name, minSeverity string) map[string]interface{} {
+				return map[string]interface{}{
+					"name":     name,
+					"criteria": []interface{}{map[string]interface{}{"min_severity": minSeverity}},
+					"actions": []interface{}{
+						map[string]interface{}{
+							"block_download": []interface{}{
+								map[string]interface{}{"unscanned": false, "active": true},
+							},
+						},
+					},
+				}
+			}
+			d := schema.TestResourceDataRaw(t, resourceXraySecurityPolicyV2().Schema, map[string]interface{}{
+				"name":                  "test-policy",
+				"type":                  "security",
+				"ignore_external_rules": true,
+				"rule":                  []interface{}{rule("baseline", "Medium"), rule("second", "Low")},
+			})
+			d.SetId("test-policy")
+
+			diags := resourceXrayPolicyUpdate(context.Background(), d, restyClient)
+			if len(tc.expectedError) > 0 {
+				if !diags.HasError() || diags[0].Summary != tc.expectedError {
+					t.Fatalf("expected error %q, got: %v", tc.expectedError, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			var policy struct {
+				Rules []json.RawMessage `json:"rules"`
+			}
+			if err := json.Unmarshal(xray.policies["test-policy"], &policy); err != nil {
+				t.Fatal(err)
+			}
+			if len(policy.Rules) != 3 || string(policy.Rules[1]) != externalRule {
+				t.Fatalf("expected the external rule to be sent back unchanged, got %s", xray.policies["test-policy"])
+			}
+			var second PolicyRule
+			if err := json.Unmarshal(policy.Rules[2], &second); err != nil {
+				t.Fatal(err)
+			}
+			if second.Name != "second" || second.Priority != 2 {
+				t.Errorf("expected the new managed rule to be added with priority 2, got %+v", second)
+			}
+		})
+	}
+}
@@ -0,0 +1,669 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jfrog/terraform-provider-shared/util"
+	"github.com/jfrog/terraform-provider-shared/validator"
+)
+
+func resourceXrayPolicySet() *schema.Resource {
+	watchSchema := resourceXrayWatch().Schema
+	// The watch is renamed by creating the new watch and deleting the old one, see applyPolicySet
+	watchSchema["name"].ForceNew = false
+
+	return &schema.Resource{
+		CreateContext: resourceXrayPolicySetCreate,
+		ReadContext:   resourceXrayPolicySetRead,
+		UpdateContext: resourceXrayPolicySetUpdate,
+		DeleteContext: resourceXrayPolicySetDelete,
+		Description: "Manages several Xray policies, and optionally the watch they are assigned to, as one unit. " +
+			"The policies are created and updated before the watch, and deleted after it. " +
+			"If any of the changes fails, the changes already made are rolled back, so Xray is not left half-configured.",
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceXrayPolicySetImport,
+		},
+
+		CustomizeDiff: policySetDiff,
+
+		Schema: util.MergeMaps(
+			getProjectKeySchema(true, "Changing the project key destroys the policies and the watch in the old project and creates them in the new one."),
+			map[string]*schema.Schema{
+				"name": {
+					Type:             schema.TypeString,
+					Required:         true,
+					ForceNew:         true,
+					Description:      "Name of the policy set. It's only used to identify the set in Terraform.",
+					ValidateDiagFunc: validator.StringIsNotEmpty,
+				},
+				"policy": {
+					Type:        schema.TypeList,
+					Required:    true,
+					MinItems:    1,
+					Description: "The policies of the set, in the format of the `xray_policy` resource.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"name": {
+								Type:             schema.TypeString,
+								Required:         true,
+								Description:      "Name of the policy (must be unique)",
+								ValidateDiagFunc: validator.StringIsNotEmpty,
+							},
+							"description": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Description: "More verbose description of the policy",
+							},
+							"type": {
+								Type:             schema.TypeString,
+								Required:         true,
+								Description:      "Type of the policy: `security`, `license`, `operational_risk` or `exposures`. The type of an existing policy can't be changed: rename the policy to replace it.",
+								ValidateDiagFunc: validator.StringInSlice(true, policyTypes...),
+								DiffSuppressFunc: suppressCaseDiff,
+							},
+							"rules_json": {
+								Type:             schema.TypeString,
+								Required:         true,
+								Description:      "The rules of the policy as a JSON array, in the format of the `rules` of the Xray policies API. See the `xray_policy` resource.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
+								DiffSuppressFunc: suppressEquivalentJSONDiff,
+							},
+						},
+					},
+				},
+				"watch": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Description: "The watch the policies of the set are assigned to. Changing the name of the watch creates the new watch before deleting the old one.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"name":             watchSchema["name"],
+							"description":      watchSchema["description"],
+							"active":           watchSchema["active"],
+							"watch_resource":   watchSchema["watch_resource"],
+							"watch_recipients": watchSchema["watch_recipients"],
+						},
+					},
+				},
+			},
+		),
+	}
+}
+
+// resourceXrayPolicySetImport imports a policy set from an ID in the format `name:policy_names`, `name:policy_names:watch_name`
+// or `name:policy_names:watch_name:project_key`, where policy_names are the names of the policies separated by commas.
+// The policy set isn't an Xray object, so its policies and watch can't be found from its name.
+func resourceXrayPolicySetImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) < 2 || len(parts) > 4 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected name:policy_names, name:policy_names:watch_name or name:policy_names:watch_name:project_key", d.Id())
+	}
+
+	var policies []interface{}
+	for _, name := range strings.Split(parts[1], ",") {
+		policies = append(policies, map[string]interface{}{"name": name})
+	}
+	if err := d.Set("policy", policies); err != nil {
+		return nil, err
+	}
+	if len(parts) > 2 && len(parts[2]) > 0 {
+		if err := d.Set("watch", []interface{}{map[string]interface{}{"name": parts[2]}}); err != nil {
+			return nil, err
+		}
+	}
+	if len(parts) > 3 {
+		if err := d.Set("project_key", parts[3]); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.Set("name", parts[0]); err != nil {
+		return nil, err
+	}
+	d.SetId(parts[0])
+
+	return []*schema.ResourceData{d}, nil
+}
+
+var policySetDiff = func(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	projectKey := diff.Get("project_key").(string)
+
+	// Xray doesn't change the type of a policy, and the policy can't be replaced while the watch uses it
+	previousTypes := map[string]string{}
+	previousPolicies, _ := diff.GetChange("policy")
+	for _, raw := range previousPolicies.([]interface{}) {
+		if policy, ok := raw.(map[string]interface{}); ok {
+			previousTypes[policy["name"].(string)] = canonicalEnumValue(policy["type"].(string), policyTypes)
+		}
+	}
+
+	names := map[string]int{}
+	for idx, raw := range diff.Get("policy").([]interface{}) {
+		policy, ok := raw.(map[string]interface{})
+		if !ok || len(policy["name"].(string)) == 0 {
+			continue
+		}
+		name := policy["name"].(string)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("policy.%d.name: policy name '%s' is already used by policy.%d", idx, name, other)
+		}
+		names[name] = idx
+		policyType := canonicalEnumValue(policy["type"].(string), policyTypes)
+		if previousType, ok := previousTypes[name]; ok && len(policyType) > 0 && previousType != policyType {
+			return fmt.Errorf("policy.%d.type: the type of policy '%s' can't be changed from '%s' to '%s'. Rename the policy to replace it", idx, name, previousType, policyType)
+		}
+		recordPlannedObject("policy", projectKey, name, policyType)
+	}
+
+	if watch, ok := diff.Get("watch").([]interface{}); ok && len(watch) > 0 && watch[0] != nil {
+		recordPlannedObject("watch", projectKey, watch[0].(map[string]interface{})["name"].(string), "")
+	}
+
+	return nil
+}
+
+func unpackPolicySetPolicies(configured []interface{}, projectKey string) []GenericPolicy {
+	var policies []GenericPolicy
+	for _, raw := range configured {
+		p := raw.(map[string]interface{})
+		policies = append(policies, GenericPolicy{
+			Name:        p["name"].(string),
+			Type:        canonicalEnumValue(p["type"].(string), policyTypes),
+			ProjectKey:  projectKey,
+			Description: p["description"].(string),
+			Rules:       json.RawMessage(p["rules_json"].(string)),
+		})
+	}
+
+	return policies
+}
+
+// unpackPolicySetWatch returns the watch of the set, with all the policies of the set assigned, or nil
+func unpackPolicySetWatch(configured []interface{}, policies []GenericPolicy, projectKey string) *Watch {
+	if len(configured) == 0 || configured[0] == nil {
+		return nil
+	}
+	w := configured[0].(map[string]interface{})
+
+	watch := Watch{
+		ProjectKey: projectKey,
+		GeneralData: WatchGeneralData{
+			Name:        w["name"].(string),
+			Description: w["description"].(string),
+			Active:      w["active"].(bool),
+		},
+		WatchRecipients: util.CastToStringArr(w["watch_recipients"].(*schema.Set).List()),
+	}
+	for _, res := range w["watch_resource"].(*schema.Set).List() {
+		watch.ProjectResources.Resources = append(watch.ProjectResources.Resources, unpackProjectResource(res))
+	}
+	for _, policy := range policies {
+		watch.AssignedPolicies = append(watch.AssignedPolicies, WatchAssignedPolicy{
+			Name: policy.Name,
+			Type: policy.Type,
+		})
+	}
+	addWatchBuildRepo(&watch)
+
+	return &watch
+}
+
+// policySetTransaction applies the changes of a policy set to Xray and records how to undo each of
+// them, so the changes already made can be rolled back when a later change fails
+type policySetTransaction struct {
+	client     *resty.Client
+	projectKey string
+	undo       []policySetUndo
+}
+
+type policySetUndo struct {
+	description string
+	apply       func() error
+}
+
+func (t *policySetTransaction) request() (*resty.Request, error) {
+	return getRestyRequest(t.client, t.projectKey)
+}
+
+func (t *policySetTransaction) getPolicy(name string) (*GenericPolicy, error) {
+	policy := GenericPolicy{}
+
+	req, err := t.request()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = req.
+		SetResult(&policy).
+		SetPathParams(map[string]string{
+			"name": name,
+		}).
+		Get("xray/api/v2/policies/{name}")
+	if err != nil {
+		return nil, err
+	}
+	// Computed fields are not sent back
+	policy.Author = ""
+	policy.Created = ""
+	policy.Modified = ""
+
+	return &policy, nil
+}
+
+func (t *policySetTransaction) postPolicy(policy GenericPolicy) error {
+	req, err := t.request()
+	if err != nil {
+		return err
+	}
+
+	_, err = req.SetBody(policy).Post("xray/api/v2/policies")
+	return err
+}
+
+func (t *policySetTransaction) putPolicy(policy GenericPolicy) error {
+	req, err := t.request()
+	if err != nil {
+		return err
+	}
+
+	_, err = req.
+		SetBody(policy).
+		SetPathParams(map[string]string{
+			"name": policy.Name,
+		}).
+		Put("xray/api/v2/policies/{name}")
+	return err
+}
+
+func (t *policySetTransaction) deletePolicy(name string) error {
+	req, err := t.request()
+	if err != nil {
+		return err
+	}
+
+	_, err = req.
+		SetPathParams(map[string]string{
+			"name": name,
+		}).
+		Delete("xray/api/v2/policies/{name}")
+	return err
+}
+
+func (t *policySetTransaction) getWatch(name string) (*Watch, error) {
+	watch := Watch{}
+
+	req, err := t.request()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = req.
+		SetResult(&watch).
+		SetPathParams(map[string]string{
+			"name": name,
+		}).
+		Get("xray/api/v2/watches/{name}")
+	if err != nil {
+		return nil, err
+	}
+
+	return &watch, nil
+}
+
+func (t *policySetTransaction) postWatch(watch Watch) error {
+	req, err := t.request()
+	if err != nil {
+		return err
+	}
+
+	_, err = req.SetBody(watch).Post("xray/api/v2/watches")
+	return err
+}
+
+func (t *policySetTransaction) putWatch(watch Watch) error {
+	req, err := t.request()
+	if err != nil {
+		return err
+	}
+
+	_, err = req.
+		SetBody(watch).
+		SetPathParams(map[string]string{
+			"name": watch.GeneralData.Name,
+		}).
+		Put("xray/api/v2/watches/{name}")
+	return err
+}
+
+func (t *policySetTransaction) deleteWatch(name string) error {
+	req, err := t.request()
+	if err != nil {
+		return err
+	}
+
+	_, err = req.
+		SetPathParams(map[string]string{
+			"name": name,
+		}).
+		Delete("xray/api/v2/watches/{name}")
+	return err
+}
+
+func (t *policySetTransaction) createPolicy(policy GenericPolicy) error {
+	if err := t.postPolicy(policy); err != nil {
+		return fmt.Errorf("failed to create policy %s: %s", policy.Name, err)
+	}
+	t.undo = append(t.undo, policySetUndo{
+		description: fmt.Sprintf("delete policy %s", policy.Name),
+		apply:       func() error { return t.deletePolicy(policy.Name) },
+	})
+
+	return nil
+}
+
+func (t *policySetTransaction) updatePolicy(policy GenericPolicy) error {
+	previous, err := t.getPolicy(policy.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read policy %s: %s", policy.Name, err)
+	}
+	if err := t.putPolicy(policy); err != nil {
+		return fmt.Errorf("failed to update policy %s: %s", policy.Name, err)
+	}
+	t.undo = append(t.undo, policySetUndo{
+		description: fmt.Sprintf("restore policy %s", policy.Name),
+		apply:       func() error { return t.putPolicy(*previous) },
+	})
+
+	return nil
+}
+
+func (t *policySetTransaction) removePolicy(name string) error {
+	previous, err := t.getPolicy(name)
+	if err != nil {
+		return fmt.Errorf("failed to read policy %s: %s", name, err)
+	}
+	if err := t.deletePolicy(name); err != nil {
+		return fmt.Errorf("failed to delete policy %s: %s", name, err)
+	}
+	t.undo = append(t.undo, policySetUndo{
+		description: fmt.Sprintf("recreate policy %s", name),
+		apply:       func() error { return t.postPolicy(*previous) },
+	})
+
+	return nil
+}
+
+func (t *policySetTransaction) createWatch(watch Watch) error {
+	if err := t.postWatch(watch); err != nil {
+		return fmt.Errorf("failed to create watch %s: %s", watch.GeneralData.Name, err)
+	}
+	t.undo = append(t.undo, policySetUndo{
+		description: fmt.Sprintf("delete watch %s", watch.GeneralData.Name),
+		apply:       func() error { return t.deleteWatch(watch.GeneralData.Name) },
+	})
+
+	return nil
+}
+
+func (t *policySetTransaction) updateWatch(watch Watch) error {
+	previous, err := t.getWatch(watch.GeneralData.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read watch %s: %s", watch.GeneralData.Name, err)
+	}
+	if err := t.putWatch(watch); err != nil {
+		return fmt.Errorf("failed to update watch %s: %s", watch.GeneralData.Name, err)
+	}
+	t.undo = append(t.undo, policySetUndo{
+		description: fmt.Sprintf("restore watch %s", watch.GeneralData.Name),
+		apply:       func() error { return t.putWatch(*previous) },
+	})
+
+	return nil
+}
+
+func (t *policySetTransaction) removeWatch(name string) error {
+	previous, err := t.getWatch(name)
+	if err != nil {
+		return fmt.Errorf("failed to read watch %s: %s", name, err)
+	}
+	if err := t.deleteWatch(name); err != nil {
+		return fmt.Errorf("failed to delete watch %s: %s", name, err)
+	}
+	t.undo = append(t.undo, policySetUndo{
+		description: fmt.Sprintf("recreate watch %s", name),
+		apply:       func() error { return t.postWatch(*previous) },
+	})
+
+	return nil
+}
+
+// rollback undoes the changes in the reverse order and returns the errors of the changes which
+// couldn't be undone
+func (t *policySetTransaction) rollback() []string {
+	var errs []string
+	for idx := len(t.undo) - 1; idx >= 0; idx-- {
+		if err := t.undo[idx].apply(); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to %s: %s", t.undo[idx].description, err))
+		}
+	}
+	t.undo = nil
+
+	return errs
+}
+
+// rollbackDiagnostics rolls back the transaction after err and returns the diagnostics describing both
+func (t *policySetTransaction) rollbackDiagnostics(err error) diag.Diagnostics {
+	rollbackErrs := t.rollback()
+	if len(rollbackErrs) > 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  err.Error(),
+			Detail: fmt.Sprintf("The rollback of the changes already made failed, Xray is left partially configured:\n%s",
+				strings.Join(rollbackErrs, "\n")),
+		}}
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  err.Error(),
+		Detail:   "The changes already made were rolled back.",
+	}}
+}
+
+// applyPolicySet applies the changes from the previous to the next policies and watch of the set:
+// the policies are created or updated first, then the watch, then the removed policies are deleted.
+func applyPolicySet(t *policySetTransaction, previousPolicies, nextPolicies []GenericPolicy, previousWatch, nextWatch *Watch) error {
+	previousByName := map[string]GenericPolicy{}
+	for _, policy := range previousPolicies {
+		previousByName[policy.Name] = policy
+	}
+	nextByName := map[string]bool{}
+	for _, policy := range nextPolicies {
+		nextByName[policy.Name] = true
+	}
+
+	for _, policy := range nextPolicies {
+		previous, ok := previousByName[policy.Name]
+		if !ok {
+			if err := t.createPolicy(policy); err != nil {
+				return err
+			}
+			continue
+		}
+		// The rules are compared as JSON, so a change of their formatting doesn't update the policy
+		if !equivalentJSON(string(previous.Rules), string(policy.Rules)) || previous.Description != policy.Description || previous.Type != policy.Type {
+			if err := t.updatePolicy(policy); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch {
+	case previousWatch == nil && nextWatch != nil:
+		if err := t.createWatch(*nextWatch); err != nil {
+			return err
+		}
+	case previousWatch != nil && nextWatch == nil:
+		if err := t.removeWatch(previousWatch.GeneralData.Name); err != nil {
+			return err
+		}
+	case previousWatch != nil && previousWatch.GeneralData.Name != nextWatch.GeneralData.Name:
+		if err := t.createWatch(*nextWatch); err != nil {
+			return err
+		}
+		if err := t.removeWatch(previousWatch.GeneralData.Name); err != nil {
+			return err
+		}
+	case previousWatch != nil && !reflect.DeepEqual(previousWatch, nextWatch):
+		if err := t.updateWatch(*nextWatch); err != nil {
+			return err
+		}
+	}
+
+	for _, policy := range previousPolicies {
+		if !nextByName[policy.Name] {
+			if err := t.removePolicy(policy.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func resourceXrayPolicySetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	projectKey := d.Get("project_key").(string)
+	policies := unpackPolicySetPolicies(d.Get("policy").([]interface{}), projectKey)
+	watch := unpackPolicySetWatch(d.Get("watch").([]interface{}), policies, projectKey)
+
+	t := &policySetTransaction{client: m.(*resty.Client), projectKey: projectKey}
+	if err := applyPolicySet(t, nil, policies, nil, watch); err != nil {
+		return t.rollbackDiagnostics(err)
+	}
+
+	d.SetId(d.Get("name").(string))
+	return resourceXrayPolicySetRead(ctx, d, m)
+}
+
+func resourceXrayPolicySetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	t := &policySetTransaction{client: m.(*resty.Client), projectKey: d.Get("project_key").(string)}
+
+	var policies []interface{}
+	for _, raw := range d.Get("policy").([]interface{}) {
+		current := raw.(map[string]interface{})
+		name := current["name"].(string)
+
+		req, err := t.request()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		policy := GenericPolicy{}
+		resp, err := req.
+			SetResult(&policy).
+			SetPathParams(map[string]string{
+				"name": name,
+			}).
+			Get("xray/api/v2/policies/{name}")
+		if err != nil {
+			if resp != nil && resp.StatusCode() == http.StatusNotFound {
+				tflog.Warn(ctx, fmt.Sprintf("Xray policy (%s) of policy set (%s) not found, removing from state", name, d.Id()))
+				continue
+			}
+			return diag.FromErr(err)
+		}
+
+		rulesJSON, err := readRulesJSON(current["rules_json"].(string), policy.Rules)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		policies = append(policies, map[string]interface{}{
+			"name":        policy.Name,
+			"description": policy.Description,
+			"type":        canonicalEnumValue(policy.Type, policyTypes),
+			"rules_json":  rulesJSON,
+		})
+	}
+	if err := d.Set("policy", policies); err != nil {
+		return diag.FromErr(err)
+	}
+
+	var watches []interface{}
+	if configured := d.Get("watch").([]interface{}); len(configured) > 0 && configured[0] != nil {
+		name := configured[0].(map[string]interface{})["name"].(string)
+
+		req, err := t.request()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		watch := Watch{}
+		resp, err := req.
+			SetResult(&watch).
+			SetPathParams(map[string]string{
+				"name": name,
+			}).
+			Get("xray/api/v2/watches/{name}")
+		if err != nil {
+			if resp == nil || resp.StatusCode() != http.StatusNotFound {
+				return diag.FromErr(err)
+			}
+			tflog.Warn(ctx, fmt.Sprintf("Xray watch (%s) of policy set (%s) not found, removing from state", name, d.Id()))
+		} else {
+			watches = append(watches, map[string]interface{}{
+				"name":             watch.GeneralData.Name,
+				"description":      watch.GeneralData.Description,
+				"active":           watch.GeneralData.Active,
+				"watch_resource":   packProjectResources(ctx, watch.ProjectResources),
+				"watch_recipients": watch.WatchRecipients,
+			})
+		}
+	}
+	if err := d.Set("watch", watches); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func resourceXrayPolicySetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	projectKey := d.Get("project_key").(string)
+
+	previousPolicyConfig, nextPolicyConfig := d.GetChange("policy")
+	previousWatchConfig, nextWatchConfig := d.GetChange("watch")
+	previousPolicies := unpackPolicySetPolicies(previousPolicyConfig.([]interface{}), projectKey)
+	nextPolicies := unpackPolicySetPolicies(nextPolicyConfig.([]interface{}), projectKey)
+	previousWatch := unpackPolicySetWatch(previousWatchConfig.([]interface{}), previousPolicies, projectKey)
+	nextWatch := unpackPolicySetWatch(nextWatchConfig.([]interface{}), nextPolicies, projectKey)
+
+	t := &policySetTransaction{client: m.(*resty.Client), projectKey: projectKey}
+	if err := applyPolicySet(t, previousPolicies, nextPolicies, previousWatch, nextWatch); err != nil {
+		// Keep the previous state, as the changes were rolled back
+		d.Partial(true)
+		return t.rollbackDiagnostics(err)
+	}
+
+	return resourceXrayPolicySetRead(ctx, d, m)
+}
+
+func resourceXrayPolicySetDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	projectKey := d.Get("project_key").(string)
+	policies := unpackPolicySetPolicies(d.Get("policy").([]interface{}), projectKey)
+	watch := unpackPolicySetWatch(d.Get("watch").([]interface{}), policies, projectKey)
+
+	t := &policySetTransaction{client: m.(*resty.Client), projectKey: projectKey}
+	if err := applyPolicySet(t, policies, nil, watch, nil); err != nil {
+		return t.rollbackDiagnostics(err)
+	}
+
+	return nil
+}
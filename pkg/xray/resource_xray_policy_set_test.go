@@ -0,0 +1,366 @@
+package xray
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"github.com/jfrog/terraform-provider-shared/util"
+)
+
+func testPolicySetPolicy(name, minSeverity string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       name,
+		"type":       "security",
+		"rules_json": fmt.Sprintf(`[{"name":"rule","priority":1,"criteria":{"min_severity":"%s"}}]`, minSeverity),
+	}
+}
+
+func testPolicySetWatch(name string) []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"name":   name,
+			"active": true,
+			"watch_resource": []interface{}{
+				map[string]interface{}{"type": "all-repos"},
+			},
+		},
+	}
+}
+
+func TestPolicySetCreateRollback(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.failures["POST /xray/api/v2/watches"] = true
+
+	d := schema.TestResourceDataRaw(t, resourceXrayPolicySet().Schema, map[string]interface{}{
+		"name": "test-set",
+		"policy": []interface{}{
+			testPolicySetPolicy("policy-a", "High"),
+			testPolicySetPolicy("policy-b", "Critical"),
+		},
+		"watch": testPolicySetWatch("test-watch"),
+	})
+
+	diags := resourceXrayPolicySetCreate(context.Background(), d, restyClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "failed to create watch test-watch") {
+		t.Fatalf("expected an error for the watch, got: %v", diags)
+	}
+	if diags[0].Detail != "The changes already made were rolled back." {
+		t.Errorf("unexpected detail: %s", diags[0].Detail)
+	}
+	if len(xray.policies) != 0 {
+		t.Errorf("expected the policies to be rolled back, got %v", xray.policies)
+	}
+	if d.Id() != "" {
+		t.Errorf("expected no ID, got %s", d.Id())
+	}
+
+	delete(xray.failures, "POST /xray/api/v2/watches")
+	if diags := resourceXrayPolicySetCreate(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(xray.policies) != 2 || len(xray.watches) != 1 {
+		t.Errorf("expected the policies and the watch to be created, got %v, %v", xray.policies, xray.watches)
+	}
+
+	var watch Watch
+	if err := json.Unmarshal(xray.watches["test-watch"], &watch); err != nil {
+		t.Fatal(err)
+	}
+	if len(watch.AssignedPolicies) != 2 || watch.AssignedPolicies[1] != (WatchAssignedPolicy{Name: "policy-b", Type: "security"}) {
+		t.Errorf("expected the policies to be assigned to the watch, got %v", watch.AssignedPolicies)
+	}
+}
+
+func TestPolicySetUpdateRollback(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+
+	policySetSchema := resourceXrayPolicySet().Schema
+	previous := schema.TestResourceDataRaw(t, policySetSchema, map[string]interface{}{
+		"name": "test-set",
+		"policy": []interface{}{
+			testPolicySetPolicy("policy-a", "High"),
+			testPolicySetPolicy("policy-b", "Critical"),
+		},
+		"watch": testPolicySetWatch("test-watch"),
+	})
+	if diags := resourceXrayPolicySetCreate(context.Background(), previous, restyClient); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	policyA := string(xray.policies["policy-a"])
+	policyB := string(xray.policies["policy-b"])
+
+	// policy-a is updated, policy-b is removed and policy-c is added, but the watch can't be updated
+	xray.failures["PUT /xray/api/v2/watches/test-watch"] = true
+	config := map[string]interface{}{
+		"name": "test-set",
+		"policy": []interface{}{
+			testPolicySetPolicy("policy-a", "Low"),
+			testPolicySetPolicy("policy-c", "Medium"),
+		},
+		"watch": testPolicySetWatch("test-watch"),
+	}
+	state := previous.State()
+	diff, err := schema.InternalMap(policySetSchema).Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := schema.InternalMap(policySetSchema).Data(state, diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags := resourceXrayPolicySetUpdate(context.Background(), d, restyClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "failed to update watch test-watch") {
+		t.Fatalf("expected an error for the watch, got: %v", diags)
+	}
+	if len(xray.policies) != 2 || string(xray.policies["policy-a"]) != policyA || string(xray.policies["policy-b"]) != policyB {
+		t.Errorf("expected the policies to be restored, got %v", xray.policies)
+	}
+
+	delete(xray.failures, "PUT /xray/api/v2/watches/test-watch")
+	if diags := resourceXrayPolicySetUpdate(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, ok := xray.policies["policy-b"]; ok || len(xray.policies) != 2 {
+		t.Errorf("expected policy-b to be replaced by policy-c, got %v", xray.policies)
+	}
+}
+
+func TestPolicySetRollbackFailure(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.failures["POST /xray/api/v2/watches"] = true
+	xray.failures["DELETE /xray/api/v2/policies/policy-a"] = true
+
+	d := schema.TestResourceDataRaw(t, resourceXrayPolicySet().Schema, map[string]interface{}{
+		"name":   "test-set",
+		"policy": []interface{}{testPolicySetPolicy("policy-a", "High")},
+		"watch":  testPolicySetWatch("test-watch"),
+	})
+
+	diags := resourceXrayPolicySetCreate(context.Background(), d, restyClient)
+	if !diags.HasError() || !strings.Contains(diags[0].Detail, "failed to delete policy policy-a") {
+		t.Errorf("expected the rollback error in the detail, got: %v", diags)
+	}
+}
+
+func TestApplyPolicySetEquivalentRules(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.failures["PUT /xray/api/v2/policies/policy-a"] = true
+
+	previous := []GenericPolicy{{Name: "policy-a", Type: "security", Rules: json.RawMessage(`[{"name":"rule","priority":1}]`)}}
+	next := []GenericPolicy{{Name: "policy-a", Type: "security", Rules: json.RawMessage(`[ {"priority": 1, "name": "rule"} ]`)}}
+
+	t.Run("reformatted rules", func(t *testing.T) {
+		if err := applyPolicySet(&policySetTransaction{client: restyClient}, previous, next, nil, nil); err != nil {
+			t.Errorf("expected the policy not to be updated, got: %s", err)
+		}
+	})
+
+	t.Run("changed rules", func(t *testing.T) {
+		next[0].Rules = json.RawMessage(`[{"name":"rule","priority":2}]`)
+		if err := applyPolicySet(&policySetTransaction{client: restyClient}, previous, next, nil, nil); err == nil {
+			t.Errorf("expected the policy to be updated")
+		}
+	})
+}
+
+func TestPolicySetDiffTypeChange(t *testing.T) {
+	defer resetPlannedObjects()
+
+	state := &terraform.InstanceState{
+		ID: "test-set",
+		Attributes: map[string]string{
+			"id":                  "test-set",
+			"name":                "test-set",
+			"policy.#":            "1",
+			"policy.0.name":       "policy-a",
+			"policy.0.type":       "security",
+			"policy.0.rules_json": `[{"name":"rule","priority":1,"criteria":{"min_severity":"High"}}]`,
+		},
+	}
+
+	testCases := []struct {
+		policyType    string
+		expectedError string
+	}{
+		{"Security", ""},
+		{"license", "policy.0.type: the type of policy 'policy-a' can't be changed from 'security' to 'license'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.policyType, func(t *testing.T) {
+			policy := testPolicySetPolicy("policy-a", "High")
+			policy["type"] = tc.policyType
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"name":   "test-set",
+				"policy": []interface{}{policy},
+			})
+
+			_, err := resourceXrayPolicySet().Diff(context.Background(), state, config, nil)
+			if len(tc.expectedError) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Fatalf("expected error containing %q, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestPolicySetImport(t *testing.T) {
+	testCases := []struct {
+		id                 string
+		expectedPolicies   int
+		expectedWatch      string
+		expectedProjectKey string
+		expectedError      bool
+	}{
+		{"test-set:policy-a,policy-b", 2, "", "", false},
+		{"test-set:policy-a:test-watch", 1, "test-watch", "", false},
+		{"test-set:policy-a::myproj", 1, "", "myproj", false},
+		{"test-set", 0, "", "", true},
+		{":policy-a", 0, "", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			d := resourceXrayPolicySet().TestResourceData()
+			d.SetId(tc.id)
+
+			_, err := resourceXrayPolicySetImport(context.Background(), d, nil)
+			if tc.expectedError {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if d.Id() != "test-set" || d.Get("name") != "test-set" {
+				t.Errorf("unexpected ID %s", d.Id())
+			}
+			if len(d.Get("policy").([]interface{})) != tc.expectedPolicies || d.Get("project_key") != tc.expectedProjectKey {
+				t.Errorf("unexpected attributes: %v, %s", d.Get("policy"), d.Get("project_key"))
+			}
+			if watchName, _ := d.Get("watch.0.name").(string); watchName != tc.expectedWatch {
+				t.Errorf("expected watch %q, got %q", tc.expectedWatch, watchName)
+			}
+		})
+	}
+}
+
+func TestAccPolicySet_full(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-set-", "xray_policy_set")
+
+	testData := map[string]string{
+		"resource_name":   resourceName,
+		"policy_a":        fmt.Sprintf("terraform-policy-set-a-%d", test.RandomInt()),
+		"policy_b":        fmt.Sprintf("terraform-policy-set-b-%d", test.RandomInt()),
+		"watch_name":      fmt.Sprintf("xray-watch-%d", test.RandomInt()),
+		"min_severity":    "High",
+		"watch_active":    "true",
+		"watch_resource":  "all-repos",
+		"second_policy":   "true",
+		"second_severity": "Critical",
+	}
+
+	template := `resource "xray_policy_set" "{{ .resource_name }}" {
+		name = "{{ .resource_name }}"
+
+		policy {
+			name       = "{{ .policy_a }}"
+			type       = "security"
+			rules_json = jsonencode([{
+				name     = "rule"
+				priority = 1
+				criteria = { min_severity = "{{ .min_severity }}" }
+				actions  = { block_download = { active = true, unscanned = false } }
+			}])
+		}
+		{{ if eq .second_policy "true" }}
+		policy {
+			name       = "{{ .policy_b }}"
+			type       = "security"
+			rules_json = jsonencode([{
+				name     = "rule"
+				priority = 1
+				criteria = { min_severity = "{{ .second_severity }}" }
+				actions  = { fail_build = true }
+			}])
+		}
+		{{ end }}
+		watch {
+			name   = "{{ .watch_name }}"
+			active = {{ .watch_active }}
+
+			watch_resource {
+				type = "{{ .watch_resource }}"
+			}
+		}
+	}`
+
+	updatedTestData := util.MergeMaps(testData)
+	updatedTestData["min_severity"] = "Medium"
+	updatedTestData["second_policy"] = "false"
+	updatedTestData["watch_active"] = "false"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		CheckDestroy: func(s *terraform.State) error {
+			if err := verifyPolicySetObjectDeleted(testData["watch_name"], testCheckWatch); err != nil {
+				return err
+			}
+			return verifyPolicySetObjectDeleted(testData["policy_a"], testCheckPolicy)
+		},
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "policy.#", "2"),
+					resource.TestCheckResourceAttr(fqrn, "policy.1.name", testData["policy_b"]),
+					resource.TestCheckResourceAttr(fqrn, "watch.0.name", testData["watch_name"]),
+					resource.TestCheckResourceAttr(fqrn, "watch.0.active", "true"),
+				),
+			},
+			{
+				Config: util.ExecuteTemplate(fqrn, template, updatedTestData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "policy.#", "1"),
+					resource.TestCheckResourceAttr(fqrn, "watch.0.active", "false"),
+				),
+			},
+		},
+	})
+}
+
+func verifyPolicySetObjectDeleted(name string, check CheckFun) error {
+	provider, _ := testAccProviders()["xray"]()
+	provider.Configure(context.Background(), terraform.NewResourceConfigRaw(nil))
+	c := provider.Meta().(*resty.Client)
+	resp, err := check(name, c.R())
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	return fmt.Errorf("error: %s still exists", name)
+}
@@ -0,0 +1,159 @@
+package xray
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/jfrog/terraform-provider-shared/test"
+	"github.com/jfrog/terraform-provider-shared/util"
+)
+
+func TestReadRulesJSON(t *testing.T) {
+	current := `[{"name": "rule-1", "priority": 1, "criteria": {"min_severity": "High"}}]`
+
+	testCases := []struct {
+		name     string
+		remote   string
+		expected string
+	}{
+		{
+			name:     "same rules with another key order",
+			remote:   `[{"criteria": {"min_severity": "High"}, "priority": 1, "name": "rule-1"}]`,
+			expected: current,
+		},
+		{
+			name:     "defaults added by Xray",
+			remote:   `[{"name": "rule-1", "priority": 1, "criteria": {"min_severity": "High", "fix_version_dependant": false}, "actions": {"fail_build": false}}]`,
+			expected: current,
+		},
+		{
+			name:     "enum value in the case of Xray",
+			remote:   `[{"name": "rule-1", "priority": 1, "criteria": {"min_severity": "high"}}]`,
+			expected: current,
+		},
+		{
+			name:     "rule name in another case",
+			remote:   `[{"name": "Rule-1", "priority": 1, "criteria": {"min_severity": "High"}}]`,
+			expected: `[{"criteria":{"min_severity":"High"},"name":"Rule-1","priority":1}]`,
+		},
+		{
+			name:     "changed value",
+			remote:   `[{"name": "rule-1", "priority": 1, "criteria": {"min_severity": "Low"}}]`,
+			expected: `[{"criteria":{"min_severity":"Low"},"name":"rule-1","priority":1}]`,
+		},
+		{
+			name:     "added rule",
+			remote:   `[{"name": "rule-1", "priority": 1, "criteria": {"min_severity": "High"}}, {"name": "rule-2", "priority": 2, "criteria": {"min_severity": "Low"}}]`,
+			expected: `[{"criteria":{"min_severity":"High"},"name":"rule-1","priority":1},{"criteria":{"min_severity":"Low"},"name":"rule-2","priority":2}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := readRulesJSON(current, json.RawMessage(tc.remote))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadRulesJSONInAnotherOrder(t *testing.T) {
+	current := `[{"name": "rule-2", "priority": 2, "criteria": {"min_severity": "Low"}}, {"name": "rule-1", "priority": 1, "criteria": {"min_severity": "High"}}]`
+	remote := `[{"name": "rule-1", "priority": 1, "criteria": {"min_severity": "high"}}, {"name": "rule-2", "priority": 2, "criteria": {"min_severity": "low"}}]`
+
+	actual, err := readRulesJSON(current, json.RawMessage(remote))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if actual != current {
+		t.Errorf("expected the rules sorted by Xray to match %s, got %s", current, actual)
+	}
+}
+
+func TestSuppressEquivalentJSONDiff(t *testing.T) {
+	if !suppressEquivalentJSONDiff("", `[{"a": 1, "b": [true]}]`, `[ {"b": [true], "a": 1.0} ]`, nil) {
+		t.Error("expected equivalent JSON to be suppressed")
+	}
+	if suppressEquivalentJSONDiff("", `[{"a": 1}]`, `[{"a": 2}]`, nil) {
+		t.Error("expected different JSON not to be suppressed")
+	}
+	if suppressEquivalentJSONDiff("", "", `[]`, nil) {
+		t.Error("expected invalid JSON not to be suppressed")
+	}
+}
+
+const genericPolicyTemplate = `resource "xray_policy" "{{ .resource_name }}" {
+	name        = "{{ .policy_name }}"
+	description = "{{ .policy_description }}"
+	type        = "license"
+	rules_json  = jsonencode([
+		{
+			name     = "{{ .rule_name }}"
+			priority = 1
+			criteria = {
+				banned_licenses = ["GPL-3.0"]
+				allow_unknown   = false
+			}
+			actions = {
+				fail_build = false
+				block_download = {
+					unscanned = false
+					active    = false
+				}
+			}
+		}
+	])
+}`
+
+func TestAccPolicy_rulesJSON(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_policy")
+
+	testData := map[string]string{
+		"resource_name":      resourceName,
+		"policy_name":        fmt.Sprintf("terraform-generic-policy-%d", test.RandomInt()),
+		"policy_description": "policy created by xray acceptance tests",
+		"rule_name":          fmt.Sprintf("test-generic-rule-%d", test.RandomInt()),
+	}
+	config := util.ExecuteTemplate(fqrn, genericPolicyTemplate, testData)
+
+	updatedTestData := util.MergeMaps(testData)
+	updatedTestData["policy_description"] = "New description"
+	updatedConfig := util.ExecuteTemplate(fqrn, genericPolicyTemplate, updatedTestData)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "name", testData["policy_name"]),
+					resource.TestCheckResourceAttr(fqrn, "type", "license"),
+					resource.TestCheckResourceAttrSet(fqrn, "author"),
+				),
+			},
+			{
+				// Xray adds the default values of the other criteria and actions, which must not show a diff
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				Config: updatedConfig,
+				Check:  resource.TestCheckResourceAttr(fqrn, "description", "New description"),
+			},
+			{
+				ResourceName:            fqrn,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"rules_json"},
+			},
+		},
+	})
+}
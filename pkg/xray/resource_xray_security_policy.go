@@ -1,51 +1,113 @@
 package xray
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/jfrog/terraform-provider-shared/util"
 	"github.com/jfrog/terraform-provider-shared/validator"
 )
 
-func resourceXraySecurityPolicyV2() *schema.Resource {
-	var criteriaSchema = map[string]*schema.Schema{
-		"min_severity": {
+var vulnerabilityIdRegex = regexp.MustCompile(`^(CVE-\d{4}-\d{4,}|XRAY-\d+)$`)
+
+// packageVersionRangeRegex matches an exact version (e.g. [1.2.3]) or a range of versions with
+// inclusive ([]) or exclusive (()) bounds (e.g. [1.0.0,2.0.0)). A missing bound must be exclusive (e.g. (,2.0.0]).
+var packageVersionRangeRegex = regexp.MustCompile(`^(\[[^\s,\[\]()]+\]|(\([^\s,\[\]()]*|\[[^\s,\[\]()]+),([^\s,\[\]()]*\)|[^\s,\[\]()]+\]))$`)
+
+var securityCriteriaSchema = map[string]*schema.Schema{
+	"min_severity": {
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "The minimum security vulnerability severity that will be impacted by the policy.",
+		ValidateDiagFunc: validator.StringInSlice(true, minSeverities...),
+		DiffSuppressFunc: suppressCaseDiff,
+	},
+	"fix_version_dependant": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Default value is `false`. Issues that do not have a fixed version are not generated until a fixed version is available.",
+	},
+	"vulnerability_ids": {
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "A list of CVE IDs (e.g. `CVE-2021-44228`) or Xray IDs (e.g. `XRAY-194080`) of the vulnerabilities that trigger the rule, regardless of their severity. Cannot be set together with `min_severity` or `cvss_range`.",
+		Elem: &schema.Schema{
 			Type:             schema.TypeString,
-			Optional:         true,
-			Description:      "The minimum security vulnerability severity that will be impacted by the policy.",
-			ValidateDiagFunc: validator.StringInSlice(true, "All Severities", "Critical", "High", "Medium", "Low"),
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringMatch(vulnerabilityIdRegex, "must be a CVE ID (e.g. CVE-2021-44228) or an Xray ID (e.g. XRAY-194080)")),
 		},
-		"fix_version_dependant": {
-			Type:        schema.TypeBool,
-			Optional:    true,
-			Default:     false,
-			Description: "Default value is `false`. Issues that do not have a fixed version are not generated until a fixed version is available.",
+	},
+	"applicable_cves_only": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: fmt.Sprintf("Default value is `false`. Only trigger the rule for the CVEs which are applicable to the scanned artifact, as found by the contextual analysis of JFrog Advanced Security. Requires Xray %s or later and the contextual analysis entitlement.", applicableCvesOnlyMinXrayVersion),
+	},
+	"malicious_package": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Default value is `false`. Trigger the rule for the packages flagged as malicious by JFrog Security, regardless of any CVE severity. Cannot be set together with `min_severity`, `cvss_range`, `vulnerability_ids`, `fix_version_dependant` or `applicable_cves_only`.",
+	},
+	"package_name": {
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      "The name of the package the rule applies to. Requires `package_type`. Omit to apply the rule to all the packages.",
+		ValidateDiagFunc: validator.StringIsNotEmpty,
+	},
+	"package_type": {
+		Type:             schema.TypeString,
+		Optional:         true,
+		Description:      fmt.Sprintf("The type of the package the rule applies to: %s.", strings.Join(packageTypes, ", ")),
+		ValidateDiagFunc: validator.StringInSlice(true, packageTypes...),
+		DiffSuppressFunc: suppressCaseDiff,
+	},
+	"package_versions": {
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "The versions of the package the rule applies to, as exact versions (e.g. `[1.2.3]`) or ranges with inclusive or exclusive bounds (e.g. `[1.0.0,2.0.0)`, `(,1.5.0]`). Requires `package_name`. Omit to apply the rule to all the versions.",
+		Elem: &schema.Schema{
+			Type:             schema.TypeString,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringMatch(packageVersionRangeRegex, "must be an exact version (e.g. [1.2.3]) or a version range (e.g. [1.0.0,2.0.0))")),
 		},
-		"cvss_range": {
-			Type:        schema.TypeList,
-			Optional:    true,
-			MaxItems:    1,
-			Description: "The CVSS score range to apply to the rule. This is used for a fine-grained control, rather than using the predefined severities. The score range is based on CVSS v3 scoring, and CVSS v2 score is CVSS v3 score is not available.",
-			Elem: &schema.Resource{
-				Schema: map[string]*schema.Schema{
-					"from": {
-						Type:             schema.TypeFloat,
-						Required:         true,
-						Description:      "The beginning of the range of CVS scores (from 1-10, float) to flag.",
-						ValidateDiagFunc: validation.ToDiagFunc(validation.FloatBetween(0, 10)),
-					},
-					"to": {
-						Type:             schema.TypeFloat,
-						Required:         true,
-						Description:      "The end of the range of CVS scores (from 1-10, float) to flag. ",
-						ValidateDiagFunc: validation.ToDiagFunc(validation.FloatBetween(0, 10)),
-					},
+	},
+	"cvss_range": {
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "The CVSS score range to apply to the rule. This is used for a fine-grained control, rather than using the predefined severities. The score range is based on CVSS v3 scoring, and CVSS v2 score is CVSS v3 score is not available.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"from": {
+					Type:             schema.TypeFloat,
+					Required:         true,
+					Description:      "The beginning of the range of CVS scores (from 1-10, float) to flag.",
+					ValidateDiagFunc: validation.ToDiagFunc(validation.FloatBetween(0, 10)),
+				},
+				"to": {
+					Type:             schema.TypeFloat,
+					Required:         true,
+					Description:      "The end of the range of CVS scores (from 1-10, float) to flag. ",
+					ValidateDiagFunc: validation.ToDiagFunc(validation.FloatBetween(0, 10)),
 				},
 			},
 		},
-	}
+	},
+}
 
+func resourceXraySecurityPolicyV2() *schema.Resource {
 	return &schema.Resource{
 		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceXrayPolicyV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: policyStateUpgradeV0,
+				Version: 0,
+			},
+		},
 		CreateContext: resourceXrayPolicyCreate,
 		ReadContext:   resourceXrayPolicyRead,
 		UpdateContext: resourceXrayPolicyUpdate,
@@ -57,6 +119,14 @@ func resourceXraySecurityPolicyV2() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
-		Schema: getPolicySchema(criteriaSchema, commonActionsSchema),
+		CustomizeDiff: policyRulesDiff("security"),
+
+		Schema: util.MergeMaps(
+			getPolicySchema(securityCriteriaSchema, commonActionsSchema),
+			forceDetachOnDestroySchema,
+			ignoreExternalRulesSchema,
+			violationCountsSchema,
+			adoptExistingSchema,
+		),
 	}
 }
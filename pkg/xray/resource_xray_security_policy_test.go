@@ -31,8 +31,8 @@ var testDataSecurity = map[string]string{
 	"cvssOrSeverity":                    "cvss",
 }
 
-// Teh test will try to create a security policy with the type of "license"
-// The Policy criteria will be ignored in this case
+// The test will try to create a security policy with the type of "license"
+// The policy type must match the resource, which is verified at plan time
 func TestAccSecurityPolicy_badTypeInSecurityPolicy(t *testing.T) {
 	policyName := fmt.Sprintf("terraform-security-policy-1-%d", test.RandomInt())
 	policyDesc := "policy created by xray acceptance tests"
@@ -47,7 +47,7 @@ func TestAccSecurityPolicy_badTypeInSecurityPolicy(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config:      testAccXraySecurityPolicy_badSecurityType(policyName, policyDesc, ruleName, rangeTo),
-				ExpectError: regexp.MustCompile("Found Invalid Policy"),
+				ExpectError: regexp.MustCompile("attribute 'type' must be 'security' for this resource, got 'license'"),
 			},
 		},
 	})
@@ -94,7 +94,7 @@ func TestAccSecurityPolicy_badGracePeriod(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config:      util.ExecuteTemplate(fqrn, securityPolicyCVSS, testData),
-				ExpectError: regexp.MustCompile("Found Invalid Policy"),
+				ExpectError: regexp.MustCompile("attribute 'build_failure_grace_period_in_days' can only be set when 'fail_build' is enabled"),
 			},
 		},
 	})
@@ -173,6 +173,75 @@ func TestAccSecurityPolicy_withProjectKey(t *testing.T) {
 	})
 }
 
+// The policy is moved to another project by changing 'project_key'. It's replaced: created in the new
+// project and deleted from the old one.
+func TestAccSecurityPolicy_changeProjectKey(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	projectKey := fmt.Sprintf("testproj%d", test.RandomInt()%10000)
+	newProjectKey := fmt.Sprintf("testproj%d", test.RandomInt()%10000)
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["project_key"] = projectKey
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-11-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-11-%d", test.RandomInt())
+
+	template := `resource "xray_security_policy" "{{ .resource_name }}" {
+		name        = "{{ .policy_name }}"
+		description = "{{ .policy_description }}"
+		type        = "security"
+		project_key = "{{ .project_key }}"
+
+		rule {
+			name = "{{ .rule_name }}"
+			criteria {
+				min_severity = "{{ .min_severity }}"
+			}
+			actions {
+				block_download {
+					unscanned = {{ .block_unscanned }}
+					active = {{ .block_active }}
+				}
+			}
+		}
+	}`
+
+	config := util.ExecuteTemplate(fqrn, template, testData)
+
+	movedTestData := util.MergeMaps(testData)
+	movedTestData["project_key"] = newProjectKey
+	movedConfig := util.ExecuteTemplate(fqrn, template, movedTestData)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			CreateProject(t, projectKey)
+			CreateProject(t, newProjectKey)
+		},
+		CheckDestroy: verifyDeleted(fqrn, func(id string, request *resty.Request) (*resty.Response, error) {
+			DeleteProject(t, projectKey)
+			DeleteProject(t, newProjectKey)
+			return testCheckPolicy(id, request.SetQueryParam("projectKey", newProjectKey))
+		}),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr(fqrn, "project_key", projectKey),
+			},
+			{
+				Config: movedConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "project_key", newProjectKey),
+					verifyDeleted(fqrn, func(id string, request *resty.Request) (*resty.Response, error) {
+						return testCheckPolicy(id, request.SetQueryParam("projectKey", projectKey))
+					}),
+				),
+			},
+		},
+	})
+}
+
 // CVSS criteria, block downloading of unscanned and active
 func TestAccSecurityPolicy_createBlockDownloadTrueCVSS(t *testing.T) {
 	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
@@ -293,6 +362,126 @@ func TestAccSecurityPolicy_createBlockDownloadFalseMinSeverity(t *testing.T) {
 	})
 }
 
+// Enum attributes configured in a different casing than Xray returns must not produce a diff
+func TestAccSecurityPolicy_caseInsensitiveEnums(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	testData := util.MergeMaps(testDataSecurity)
+
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-10-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-10-%d", test.RandomInt())
+	testData["min_severity"] = "hIGH"
+
+	template := `resource "xray_security_policy" "{{ .resource_name }}" {
+		name        = "{{ .policy_name }}"
+		description = "{{ .policy_description }}"
+		type        = "Security"
+
+		rule {
+			name     = "{{ .rule_name }}"
+			priority = 1
+
+			criteria {
+				min_severity = "{{ .min_severity }}"
+			}
+
+			actions {
+				block_download {
+					unscanned = true
+					active    = true
+				}
+			}
+		}
+	}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "type", "security"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.min_severity", "High"),
+				),
+			},
+			{
+				Config:   util.ExecuteTemplate(fqrn, template, testData),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// Multiple rules without priorities, which are computed from the rule positions
+func TestAccSecurityPolicy_multipleRulesAutoPriority(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	testData := util.MergeMaps(testDataSecurity)
+
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-11-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-11-%d", test.RandomInt())
+
+	template := `resource "xray_security_policy" "{{ .resource_name }}" {
+		name        = "{{ .policy_name }}"
+		description = "{{ .policy_description }}"
+		type        = "security"
+
+		rule {
+			name = "{{ .rule_name }}-critical"
+
+			criteria {
+				min_severity = "Critical"
+			}
+
+			actions {
+				block_download {
+					unscanned = true
+					active    = true
+				}
+			}
+		}
+
+		rule {
+			name = "{{ .rule_name }}-high"
+
+			criteria {
+				min_severity = "High"
+			}
+
+			actions {
+				block_download {
+					unscanned = false
+					active    = false
+				}
+			}
+		}
+	}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.#", "2"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.name", testData["rule_name"]+"-critical"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.priority", "1"),
+					resource.TestCheckResourceAttr(fqrn, "rule.1.name", testData["rule_name"]+"-high"),
+					resource.TestCheckResourceAttr(fqrn, "rule.1.priority", "2"),
+				),
+			},
+			{
+				Config:   util.ExecuteTemplate(fqrn, template, testData),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
 // CVSS criteria, use float values for CVSS range
 func TestAccSecurityPolicy_createCVSSFloat(t *testing.T) {
 	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
@@ -392,6 +581,143 @@ resource "xray_security_policy" "test" {
 `, name, description, ruleName, allowedLicense)
 }
 
+func TestAccSecurityPolicy_vulnerabilityIds(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-14-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-14-%d", test.RandomInt())
+
+	template := `resource "xray_security_policy" "{{ .resource_name }}" {
+		name        = "{{ .policy_name }}"
+		description = "{{ .policy_description }}"
+		type        = "security"
+
+		rule {
+			name = "{{ .rule_name }}"
+			criteria {
+				vulnerability_ids = ["CVE-2021-44228", "XRAY-194080"]
+			}
+			actions {
+				block_download {
+					unscanned = {{ .block_unscanned }}
+					active = {{ .block_active }}
+				}
+			}
+		}
+	}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.vulnerability_ids.#", "2"),
+					resource.TestCheckTypeSetElemAttr(fqrn, "rule.0.criteria.0.vulnerability_ids.*", "CVE-2021-44228"),
+					resource.TestCheckTypeSetElemAttr(fqrn, "rule.0.criteria.0.vulnerability_ids.*", "XRAY-194080"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.min_severity", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSecurityPolicy_maliciousPackage(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-15-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-15-%d", test.RandomInt())
+
+	template := `resource "xray_security_policy" "{{ .resource_name }}" {
+		name        = "{{ .policy_name }}"
+		description = "{{ .policy_description }}"
+		type        = "security"
+
+		rule {
+			name = "{{ .rule_name }}"
+			criteria {
+				malicious_package = true
+			}
+			actions {
+				block_download {
+					unscanned = {{ .block_unscanned }}
+					active = {{ .block_active }}
+				}
+			}
+		}
+	}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.malicious_package", "true"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.min_severity", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSecurityPolicy_packageCriteria(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+
+	testData := util.MergeMaps(testDataSecurity)
+	testData["resource_name"] = resourceName
+	testData["policy_name"] = fmt.Sprintf("terraform-security-policy-16-%d", test.RandomInt())
+	testData["rule_name"] = fmt.Sprintf("test-security-rule-16-%d", test.RandomInt())
+
+	template := `resource "xray_security_policy" "{{ .resource_name }}" {
+		name        = "{{ .policy_name }}"
+		description = "{{ .policy_description }}"
+		type        = "security"
+
+		rule {
+			name = "{{ .rule_name }}"
+			criteria {
+				min_severity     = "High"
+				package_name     = "lodash"
+				package_type     = "npm"
+				package_versions = ["[4.0.0,4.17.21)", "(,3.10.1]"]
+			}
+			actions {
+				block_download {
+					unscanned = {{ .block_unscanned }}
+					active = {{ .block_active }}
+				}
+			}
+		}
+	}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: util.ExecuteTemplate(fqrn, template, testData),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.package_name", "lodash"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.package_type", "npm"),
+					resource.TestCheckResourceAttr(fqrn, "rule.0.criteria.0.package_versions.#", "2"),
+					resource.TestCheckTypeSetElemAttr(fqrn, "rule.0.criteria.0.package_versions.*", "[4.0.0,4.17.21)"),
+					resource.TestCheckTypeSetElemAttr(fqrn, "rule.0.criteria.0.package_versions.*", "(,3.10.1]"),
+				),
+			},
+		},
+	})
+}
+
 func verifySecurityPolicy(fqrn string, testData map[string]string, cvssOrSeverity string) resource.TestCheckFunc {
 	var commonCheckList = resource.ComposeTestCheckFunc(
 		resource.TestCheckResourceAttr(fqrn, "name", testData["policy_name"]),
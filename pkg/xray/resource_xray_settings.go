@@ -13,11 +13,12 @@ import (
 
 func resourceXraySettings() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceXrayDbSyncTimeUpdate,
+		CreateContext: resourceXrayDbSyncTimeCreate,
 		ReadContext:   resourceXrayDbSyncTimeRead,
 		UpdateContext: resourceXrayDbSyncTimeUpdate,
 		DeleteContext: resourceXrayDbSyncTimeDelete,
-		Description:   "Provides an Xray DB Sync Time resource.",
+		Description: "Provides an Xray DB Sync Time resource. The setting always exists: creating the resource records its current value in `original_db_sync_updates_time` before updating it, " +
+			"and destroying it restores this value if `restore_on_destroy` is set.",
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -30,6 +31,16 @@ func resourceXraySettings() *schema.Resource {
 				Description:      "The time of the Xray DB sync daily update job. Format HH:mm",
 				ValidateDiagFunc: matchesHoursMinutesTime,
 			},
+			"restore_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Default value is `false`. Restore the original time, in `original_db_sync_updates_time`, when the resource is destroyed. Otherwise, the time is left unchanged.",
+			},
+			"original_db_sync_updates_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The time of the Xray DB sync daily update job before the resource was created or imported.",
+			},
 		},
 	}
 }
@@ -62,8 +73,23 @@ func resourceXrayDbSyncTimeRead(_ context.Context, d *schema.ResourceData, m int
 		}
 		return diag.FromErr(err)
 	}
-	packDBSyncTime(dbSyncTime, d)
-	return nil
+	if err := recordOriginalSetting(d, "original_db_sync_updates_time", dbSyncTime.DbSyncTime); err != nil {
+		return diag.FromErr(err)
+	}
+	return packDBSyncTime(dbSyncTime, d)
+}
+
+func resourceXrayDbSyncTimeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	original := DbSyncDailyUpdatesTime{}
+	_, err := m.(*resty.Client).R().SetResult(&original).Get("xray/api/v1/configuration/dbsync/time")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := recordOriginalSetting(d, "original_db_sync_updates_time", original.DbSyncTime); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceXrayDbSyncTimeUpdate(ctx, d, m)
 }
 
 func resourceXrayDbSyncTimeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -77,8 +103,17 @@ func resourceXrayDbSyncTimeUpdate(ctx context.Context, d *schema.ResourceData, m
 }
 
 // No delete functionality provided by API for the DB sync call.
-// Delete function will remove the object from the Terraform state
+// Delete function will restore the original time if needed, and remove the object from the Terraform state
 func resourceXrayDbSyncTimeDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if original, ok := originalSettingToRestore(d, "original_db_sync_updates_time"); ok {
+		_, err := m.(*resty.Client).R().
+			SetBody(DbSyncDailyUpdatesTime{DbSyncTime: original}).
+			Put("xray/api/v1/configuration/dbsync/time")
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	d.SetId("")
 	return nil
 }
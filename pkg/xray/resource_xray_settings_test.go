@@ -1,12 +1,15 @@
 package xray
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/jfrog/terraform-provider-shared/test"
 	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func TestDbSyncTime(t *testing.T) {
@@ -42,6 +45,34 @@ func TestDbSyncTimeNegative(t *testing.T) {
 	}
 }
 
+func TestDbSyncTimeRestoreOnDestroy(t *testing.T) {
+	dbSyncTime := json.RawMessage(`{"db_sync_updates_time":"04:00"}`)
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.addConfigurationRoutes(t, "/xray/api/v1/configuration/dbsync/time", &dbSyncTime)
+
+	d := schema.TestResourceDataRaw(t, resourceXraySettings().Schema, map[string]interface{}{
+		"db_sync_updates_time": "18:45",
+		"restore_on_destroy":   true,
+	})
+	if diags := resourceXrayDbSyncTimeCreate(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if original := d.Get("original_db_sync_updates_time").(string); original != "04:00" {
+		t.Errorf("expected the original time to be recorded, got: %s", original)
+	}
+	if string(dbSyncTime) != `{"db_sync_updates_time":"18:45"}` {
+		t.Errorf("expected the time to be updated, got: %s", dbSyncTime)
+	}
+
+	if diags := resourceXrayDbSyncTimeDelete(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if string(dbSyncTime) != `{"db_sync_updates_time":"04:00"}` {
+		t.Errorf("expected the original time to be restored, got: %s", dbSyncTime)
+	}
+}
+
 func dbSyncTime(resourceName string, time string) string {
 	return fmt.Sprintf(`
 		resource "xray_settings" "%s" {
@@ -7,6 +7,107 @@ import (
 )
 
 func resourceXrayWatch() *schema.Resource {
+	var filterElem = &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "The type of filter, such as `regex` or `package-type`",
+				ValidateDiagFunc: validator.StringInSlice(true, watchFilterTypes...),
+				DiffSuppressFunc: suppressCaseDiff,
+			},
+			"value": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "The value of the filter, such as the text of the regex or name of the package type.",
+				ValidateDiagFunc: validator.StringIsNotEmpty,
+			},
+		},
+	}
+
+	var watchResourceElem = &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Type of resource to be watched. Options: `all-repos`, `repository`, `all-builds`, `build`, `project`, `all-projects`.",
+				ValidateDiagFunc: validator.StringInSlice(true, watchResourceTypes...),
+				DiffSuppressFunc: suppressCaseDiff,
+			},
+			"bin_mgr_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "default",
+				Description: "The ID number of a binary manager resource. Default value is `default`. To check the list of available binary managers, use the API call `${JFROG_URL}/xray/api/v1/binMgr` as an admin user, use `binMgrId` value. More info [here](https://www.jfrog.com/confluence/display/JFROG/Xray+REST+API#XrayRESTAPI-GetBinaryManager)",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of the build, repository or project. Xray indexing must be enabled on the repository or build",
+			},
+			"repo_type": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validator.StringInSlice(true, watchRepoTypes...),
+				DiffSuppressFunc: suppressCaseDiff,
+				Description:      "Type of repository. Only applicable when `type` is `repository`. Options: `local` or `remote`.",
+			},
+			"filter": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				MinItems:    1,
+				Description: "Filter for `regex` and `package-type` type. Works only with `all-repos` watch_resource.type.",
+				Elem:        filterElem,
+				Set:         hashResourceIgnoringCase(filterElem, "type"),
+			},
+			"ant_filter": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				MinItems:    1,
+				Description: "`ant-patterns` filter for `all-builds` and `all-projects` watch_resource.type",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"include_patterns": {
+							Type: schema.TypeList,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Required:    true,
+							MinItems:    1,
+							Description: "List of Ant patterns.",
+						},
+						"exclude_patterns": {
+							Type: schema.TypeList,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Required:    true,
+							MinItems:    1,
+							Description: "List of Ant patterns.",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var assignedPolicyElem = &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the policy that will be applied",
+			},
+			"type": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "The type of the policy - security, license, operational_risk or exposures",
+				ValidateDiagFunc: validator.StringInSlice(true, assignedPolicyTypes...),
+				DiffSuppressFunc: suppressCaseDiff,
+			},
+		},
+	}
+
 	return &schema.Resource{
 		CreateContext: resourceXrayWatchCreate,
 		ReadContext:   resourceXrayWatchRead,
@@ -21,7 +122,9 @@ func resourceXrayWatch() *schema.Resource {
 		CustomizeDiff: watchResourceDiff,
 
 		Schema: util.MergeMaps(
-			getProjectKeySchema(false, "Support repository and build watch resource types. When specifying individual repository or build they must be already assigned to the project. Build must be added as indexed resources."),
+			violationCountsSchema,
+			adoptExistingSchema,
+			getProjectKeySchema(true, "Support repository and build watch resource types. When specifying individual repository or build they must be already assigned to the project. Build must be added as indexed resources. Changing the project key destroys the watch in the old project and creates it in the new one."),
 			map[string]*schema.Schema{
 				"name": {
 					Type:             schema.TypeString,
@@ -40,87 +143,21 @@ func resourceXrayWatch() *schema.Resource {
 					Optional:    true,
 					Description: "Whether or not the watch is active",
 				},
+				"check_watched_resources": {
+					Type:             schema.TypeString,
+					Optional:         true,
+					ValidateDiagFunc: validator.StringInSlice(true, checkWatchedResourcesModes...),
+					DiffSuppressFunc: suppressCaseDiff,
+					Description: "Check the watched repositories, builds and projects in Artifactory: they must exist, match `repo_type`, be assigned to the project of the watch, and be indexed by Xray. " +
+						"With `error`, each offending resource is an error at plan time: the names which aren't known at plan time, such as the keys of the repositories created by the same apply, are skipped. " +
+						"With `warning`, each offending resource is a warning when the watch is created or updated. A federated repository matches the `local` `repo_type`. Not checked by default.",
+				},
 				"watch_resource": {
 					Type:        schema.TypeSet,
 					Required:    true,
 					Description: "Nested argument describing the resources to be watched. Defined below.",
-					Elem: &schema.Resource{
-						Schema: map[string]*schema.Schema{
-							"type": {
-								Type:             schema.TypeString,
-								Required:         true,
-								Description:      "Type of resource to be watched. Options: `all-repos`, `repository`, `all-builds`, `build`, `project`, `all-projects`.",
-								ValidateDiagFunc: validator.StringInSlice(true, "all-repos", "repository", "all-builds", "build", "project", "all-projects"),
-							},
-							"bin_mgr_id": {
-								Type:        schema.TypeString,
-								Optional:    true,
-								Default:     "default",
-								Description: "The ID number of a binary manager resource. Default value is `default`. To check the list of available binary managers, use the API call `${JFROG_URL}/xray/api/v1/binMgr` as an admin user, use `binMgrId` value. More info [here](https://www.jfrog.com/confluence/display/JFROG/Xray+REST+API#XrayRESTAPI-GetBinaryManager)",
-							},
-							"name": {
-								Type:        schema.TypeString,
-								Optional:    true,
-								Description: "The name of the build, repository or project. Xray indexing must be enabled on the repository or build",
-							},
-							"repo_type": {
-								Type:             schema.TypeString,
-								Optional:         true,
-								ValidateDiagFunc: validator.StringInSlice(true, "local", "remote"),
-								Description:      "Type of repository. Only applicable when `type` is `repository`. Options: `local` or `remote`.",
-							},
-							"filter": {
-								Type:        schema.TypeSet,
-								Optional:    true,
-								MinItems:    1,
-								Description: "Filter for `regex` and `package-type` type. Works only with `all-repos` watch_resource.type.",
-								Elem: &schema.Resource{
-									Schema: map[string]*schema.Schema{
-										"type": {
-											Type:             schema.TypeString,
-											Required:         true,
-											Description:      "The type of filter, such as `regex` or `package-type`",
-											ValidateDiagFunc: validator.StringInSlice(true, "regex", "package-type"),
-										},
-										"value": {
-											Type:             schema.TypeString,
-											Required:         true,
-											Description:      "The value of the filter, such as the text of the regex or name of the package type.",
-											ValidateDiagFunc: validator.StringIsNotEmpty,
-										},
-									},
-								},
-							},
-							"ant_filter": {
-								Type:        schema.TypeSet,
-								Optional:    true,
-								MinItems:    1,
-								Description: "`ant-patterns` filter for `all-builds` and `all-projects` watch_resource.type",
-								Elem: &schema.Resource{
-									Schema: map[string]*schema.Schema{
-										"include_patterns": {
-											Type: schema.TypeList,
-											Elem: &schema.Schema{
-												Type: schema.TypeString,
-											},
-											Required:    true,
-											MinItems:    1,
-											Description: "List of Ant patterns.",
-										},
-										"exclude_patterns": {
-											Type: schema.TypeList,
-											Elem: &schema.Schema{
-												Type: schema.TypeString,
-											},
-											Required:    true,
-											MinItems:    1,
-											Description: "List of Ant patterns.",
-										},
-									},
-								},
-							},
-						},
-					},
+					Elem:        watchResourceElem,
+					Set:         hashResourceIgnoringCase(watchResourceElem, "type", "repo_type"),
 				},
 				// Key is "assigned_policies" in the API call body. Plural is used for better reflection of the
 				// actual functionality (see HCL examples)
@@ -128,21 +165,8 @@ func resourceXrayWatch() *schema.Resource {
 					Type:        schema.TypeSet,
 					Required:    true,
 					Description: "Nested argument describing policies that will be applied. Defined below.",
-					Elem: &schema.Resource{
-						Schema: map[string]*schema.Schema{
-							"name": {
-								Type:        schema.TypeString,
-								Required:    true,
-								Description: "The name of the policy that will be applied",
-							},
-							"type": {
-								Type:             schema.TypeString,
-								Required:         true,
-								Description:      "The type of the policy - security or license",
-								ValidateDiagFunc: validator.StringInSlice(true, "security", "license"),
-							},
-						},
-					},
+					Elem:        assignedPolicyElem,
+					Set:         hashResourceIgnoringCase(assignedPolicyElem, "type"),
 				},
 				"watch_recipients": {
 					Type:        schema.TypeSet,
@@ -3,6 +3,7 @@ package xray
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 
 	"github.com/go-resty/resty/v2"
@@ -92,6 +93,16 @@ func resourceXrayWorkersCount() *schema.Resource {
 				Schema: newContentSchema,
 			},
 		},
+		"restore_on_destroy": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Default value is `false`. Restore the original workers counts, in `original_workers_count`, when the resource is destroyed. Otherwise, the workers counts are left unchanged.",
+		},
+		"original_workers_count": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The workers counts before the resource was created or imported, in the JSON format of the Xray API.",
+		},
 	}
 
 	type NewContent struct {
@@ -210,14 +221,6 @@ func resourceXrayWorkersCount() *schema.Resource {
 		}
 	}
 
-	var resourceXrayWorkersCountCreate = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-		return diag.Diagnostics{{
-			Severity: diag.Error,
-			Summary:  "Workers Count resource does not support create",
-			Detail:   "Workers Count can only be updated. To manage this resource in Terraform, use `terraform import` to import it into the state.",
-		}}
-	}
-
 	var resourceXrayWorkersCountRead = func(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 		workersCount := WorkersCount{}
 		resp, err := m.(*resty.Client).R().
@@ -230,24 +233,21 @@ func resourceXrayWorkersCount() *schema.Resource {
 		hash := sha256.Sum256(resp.Body())
 		d.SetId(fmt.Sprintf("%x", hash))
 
+		if err := recordOriginalSetting(d, "original_workers_count", string(resp.Body())); err != nil {
+			return diag.FromErr(err)
+		}
+
 		return packWorkersCount(d, workersCount)
 	}
 
-	var resourceXrayWorkersCountUpdate = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-		workersCount := unpackWorkersCount(d)
+	var putWorkersCount = func(m interface{}, workersCount interface{}) diag.Diagnostics {
 		_, err := m.(*resty.Client).R().
 			SetBody(workersCount).
 			Put("xray/api/v1/configuration/workersCount")
-
 		if err != nil {
 			return diag.FromErr(err)
 		}
 
-		diagnostic := resourceXrayWorkersCountRead(ctx, d, m)
-		if diagnostic != nil {
-			return diagnostic
-		}
-
 		return diag.Diagnostics{{
 			Severity: diag.Warning,
 			Summary:  "Xray must be restarted",
@@ -255,12 +255,40 @@ func resourceXrayWorkersCount() *schema.Resource {
 		}}
 	}
 
-	var resourceXrayWorkersCountDelete = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-		return diag.Diagnostics{{
-			Severity: diag.Error,
-			Summary:  "Workers Count resource does not support delete",
-			Detail:   "Workers Count can only be updated. To stop managing this resource in Terraform, use `terraform state rm` to removed it from the state. Then the resource can be removed from the configuration.",
-		}}
+	var resourceXrayWorkersCountCreate = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		resp, err := m.(*resty.Client).R().
+			Get("xray/api/v1/configuration/workersCount")
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := recordOriginalSetting(d, "original_workers_count", string(resp.Body())); err != nil {
+			return diag.FromErr(err)
+		}
+
+		diags := putWorkersCount(m, unpackWorkersCount(d))
+		if diags.HasError() {
+			return diags
+		}
+
+		return append(diags, resourceXrayWorkersCountRead(ctx, d, m)...)
+	}
+
+	var resourceXrayWorkersCountUpdate = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		diags := putWorkersCount(m, unpackWorkersCount(d))
+		if diags.HasError() {
+			return diags
+		}
+
+		return append(diags, resourceXrayWorkersCountRead(ctx, d, m)...)
+	}
+
+	var resourceXrayWorkersCountDelete = func(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		original, ok := originalSettingToRestore(d, "original_workers_count")
+		if !ok {
+			return nil
+		}
+
+		return putWorkersCount(m, json.RawMessage(original))
 	}
 
 	return &schema.Resource{
@@ -268,7 +296,8 @@ func resourceXrayWorkersCount() *schema.Resource {
 		ReadContext:   resourceXrayWorkersCountRead,
 		UpdateContext: resourceXrayWorkersCountUpdate,
 		DeleteContext: resourceXrayWorkersCountDelete,
-		Description:   "Configure the number of workers which enables you to control the number of workers for new content and existing content. Only works for self-hosted version!",
+		Description: "Configure the number of workers which enables you to control the number of workers for new content and existing content. Only works for self-hosted version! " +
+			"The workers counts always exist: creating the resource records their current values in `original_workers_count` before updating them, and destroying it restores these values if `restore_on_destroy` is set.",
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -1,16 +1,18 @@
 package xray
 
 import (
-	"regexp"
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/jfrog/terraform-provider-shared/test"
 	"github.com/jfrog/terraform-provider-shared/util"
 )
 
 func TestAccWorkersCount_create(t *testing.T) {
-	_, _, resourceName := test.MkNames("workers-count-", "xray_workers_count")
+	_, fqrn, resourceName := test.MkNames("workers-count-", "xray_workers_count")
 
 	params := map[string]interface{}{
 		"workersCountName": resourceName,
@@ -47,9 +49,55 @@ func TestAccWorkersCount_create(t *testing.T) {
 		ProviderFactories: testAccProviders(),
 		Steps: []resource.TestStep{
 			{
-				Config:      workersCountConfig,
-				ExpectError: regexp.MustCompile(`Workers Count resource does not support create`),
+				Config: workersCountConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "index.0.new_content", "4"),
+					resource.TestCheckResourceAttrSet(fqrn, "original_workers_count"),
+				),
 			},
 		},
 	})
 }
+
+func TestWorkersCountRestoreOnDestroy(t *testing.T) {
+	original := `{"index":{"new_content":4,"existing_content":2},"persist":{"new_content":4,"existing_content":2},"analysis":{"new_content":4,"existing_content":2},"alert":{"new_content":4,"existing_content":2},"impact_analysis":{"new_content":2},"notification":{"new_content":2}}`
+	workersCount := json.RawMessage(original)
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.addConfigurationRoutes(t, "/xray/api/v1/configuration/workersCount", &workersCount)
+
+	newExistingContent := []interface{}{
+		map[string]interface{}{"new_content": 8, "existing_content": 4},
+	}
+	newContent := []interface{}{
+		map[string]interface{}{"new_content": 3},
+	}
+	workersCountResource := resourceXrayWorkersCount()
+	d := schema.TestResourceDataRaw(t, workersCountResource.Schema, map[string]interface{}{
+		"index":              newExistingContent,
+		"persist":            newExistingContent,
+		"analysis":           newExistingContent,
+		"alert":              newExistingContent,
+		"impact_analysis":    newContent,
+		"notification":       newContent,
+		"restore_on_destroy": true,
+	})
+
+	diags := workersCountResource.CreateContext(context.Background(), d, restyClient)
+	if diags.HasError() || len(diags) != 1 || diags[0].Summary != "Xray must be restarted" {
+		t.Fatalf("expected a restart warning, got: %v", diags)
+	}
+	if d.Get("original_workers_count").(string) != original {
+		t.Errorf("expected the original workers count to be recorded, got: %s", d.Get("original_workers_count"))
+	}
+	if string(workersCount) == original {
+		t.Errorf("expected the workers count to be updated")
+	}
+
+	if diags := workersCountResource.DeleteContext(context.Background(), d, restyClient); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if string(workersCount) != original {
+		t.Errorf("expected the original workers count to be restored, got: %s", workersCount)
+	}
+}
@@ -2,10 +2,14 @@ package xray
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/jfrog/terraform-provider-shared/validator"
+	"golang.org/x/exp/slices"
 )
 
 func getRestyRequest(client *resty.Client, projectKey string) (*resty.Request, error) {
@@ -21,6 +25,50 @@ func getRestyRequest(client *resty.Client, projectKey string) (*resty.Request, e
 	return req, nil
 }
 
+// isXrayVersionAtLeast reports whether the version of Xray is minVersion or later.
+// The version of Xray is returned too, to be used in error messages.
+func isXrayVersionAtLeast(client *resty.Client, minVersion string) (bool, string, error) {
+	type XrayVersion struct {
+		Version  string `json:"xray_version"`
+		Revision string `json:"xray_revision"`
+	}
+
+	xrayVersion := XrayVersion{}
+	_, err := client.R().
+		SetResult(&xrayVersion).
+		Get("xray/api/v1/system/version")
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get the Xray version: %s", err)
+	}
+
+	current, err := version.NewVersion(xrayVersion.Version)
+	if err != nil {
+		return false, xrayVersion.Version, fmt.Errorf("failed to parse the Xray version '%s': %s", xrayVersion.Version, err)
+	}
+
+	return current.GreaterThanOrEqual(version.Must(version.NewVersion(minVersion))), xrayVersion.Version, nil
+}
+
+// isXrayFeatureEntitled reports whether the JFrog subscription is entitled to an Xray feature,
+// e.g. `contextual_analysis`.
+func isXrayFeatureEntitled(client *resty.Client, feature string) (bool, error) {
+	type Entitlement struct {
+		FeatureId string `json:"feature_id"`
+		Entitled  bool   `json:"entitled"`
+	}
+
+	entitlement := Entitlement{}
+	_, err := client.R().
+		SetResult(&entitlement).
+		SetPathParam("feature", feature).
+		Get("xray/api/v1/entitlements/feature/{feature}")
+	if err != nil {
+		return false, fmt.Errorf("failed to get the entitlement of the feature '%s': %s", feature, err)
+	}
+
+	return entitlement.Entitled, nil
+}
+
 var getProjectKeySchema = func(isForceNew bool, additionalDescription string) map[string]*schema.Schema {
 	description := fmt.Sprintf("Project key for assigning this resource to. Must be 3 - 10 lowercase alphanumeric and hyphen characters. %s", additionalDescription)
 
@@ -34,3 +82,118 @@ var getProjectKeySchema = func(isForceNew bool, additionalDescription string) ma
 		},
 	}
 }
+
+// suppressCaseDiff ignores differences in casing between the configured value of an enum
+// attribute and the canonical value returned by Xray.
+var suppressCaseDiff = func(_, old, new string, _ *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
+// canonicalEnumValue returns the entry of validValues matching value case-insensitively.
+// The value is returned unchanged if there is no match.
+func canonicalEnumValue(value string, validValues []string) string {
+	for _, validValue := range validValues {
+		if strings.EqualFold(value, validValue) {
+			return validValue
+		}
+	}
+
+	return value
+}
+
+// hashResourceIgnoringCase is a schema.SchemaSetFunc for resource which ignores the casing
+// of the given enum attributes at any nesting level, so that a set element from the
+// configuration and the same element read back from Xray end up with the same hash.
+func hashResourceIgnoringCase(resource *schema.Resource, attributes ...string) schema.SchemaSetFunc {
+	return func(v interface{}) int {
+		return schema.HashResource(resource)(lowerCaseAttributes(v, attributes))
+	}
+}
+
+func lowerCaseAttributes(v interface{}, attributes []string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for key, attr := range value {
+			if s, ok := attr.(string); ok && slices.Contains(attributes, key) {
+				m[key] = strings.ToLower(s)
+			} else {
+				m[key] = lowerCaseAttributes(attr, attributes)
+			}
+		}
+		return m
+	case []interface{}:
+		var l []interface{}
+		for _, elem := range value {
+			l = append(l, lowerCaseAttributes(elem, attributes))
+		}
+		return l
+	case *schema.Set:
+		return schema.NewSet(value.F, lowerCaseAttributes(value.List(), attributes).([]interface{}))
+	}
+
+	return v
+}
+
+// computedSchema returns a copy of a resource schema with every attribute computed, to expose
+// the attributes of a resource in a data source. Nested blocks are copied recursively.
+func computedSchema(resourceSchema map[string]*schema.Schema) map[string]*schema.Schema {
+	computed := map[string]*schema.Schema{}
+
+	for key, attr := range resourceSchema {
+		c := &schema.Schema{
+			Type:        attr.Type,
+			Computed:    true,
+			Description: attr.Description,
+		}
+
+		switch elem := attr.Elem.(type) {
+		case *schema.Resource:
+			c.Elem = &schema.Resource{
+				Schema: computedSchema(elem.Schema),
+			}
+		case *schema.Schema:
+			c.Elem = &schema.Schema{
+				Type: elem.Type,
+			}
+		}
+
+		computed[key] = c
+	}
+
+	return computed
+}
+
+// mutexKV is a set of mutexes identified by a key, to serialize the read-modify-write
+// operations on the same Xray object
+type mutexKV struct {
+	lock  sync.Mutex
+	store map[string]*sync.Mutex
+}
+
+func newMutexKV() *mutexKV {
+	return &mutexKV{
+		store: map[string]*sync.Mutex{},
+	}
+}
+
+func (m *mutexKV) Lock(key string) {
+	m.get(key).Lock()
+}
+
+func (m *mutexKV) Unlock(key string) {
+	m.get(key).Unlock()
+}
+
+func (m *mutexKV) get(key string) *sync.Mutex {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	mutex, ok := m.store[key]
+	if !ok {
+		mutex = &sync.Mutex{}
+		m.store[key] = mutex
+	}
+
+	return mutex
+}
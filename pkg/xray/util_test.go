@@ -2,11 +2,17 @@ package xray
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/jfrog/terraform-provider-shared/client"
 	"github.com/jfrog/terraform-provider-shared/test"
@@ -110,3 +116,219 @@ func DeleteProject(t *testing.T, projectKey string) {
 		t.Fatal(err)
 	}
 }
+
+func TestCanonicalEnumValue(t *testing.T) {
+	validValues := []string{"All Severities", "Critical", "High"}
+
+	for value, expected := range map[string]string{
+		"all severities": "All Severities",
+		"HIGH":           "High",
+		"Critical":       "Critical",
+		"Unknown":        "Unknown",
+	} {
+		if actual := canonicalEnumValue(value, validValues); actual != expected {
+			t.Errorf("expected %q for %q, got %q", expected, value, actual)
+		}
+	}
+}
+
+func TestHashResourceIgnoringCase(t *testing.T) {
+	resource := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {Type: schema.TypeString, Required: true},
+			"type": {Type: schema.TypeString, Required: true},
+		},
+	}
+	hash := hashResourceIgnoringCase(resource, "type")
+
+	configured := hash(map[string]interface{}{"name": "my-policy", "type": "Security"})
+	remote := hash(map[string]interface{}{"name": "my-policy", "type": "security"})
+	if configured != remote {
+		t.Errorf("expected the same hash for enum values differing only in case")
+	}
+
+	renamed := hash(map[string]interface{}{"name": "My-Policy", "type": "security"})
+	if renamed == remote {
+		t.Errorf("expected a different hash for non-enum values differing in case")
+	}
+}
+
+func TestProjectKeyForcesReplacement(t *testing.T) {
+	for name, resource := range map[string]*schema.Resource{
+		"xray_security_policy":         resourceXraySecurityPolicyV2(),
+		"xray_license_policy":          resourceXrayLicensePolicyV2(),
+		"xray_operational_risk_policy": resourceXrayOperationalRiskPolicy(),
+		"xray_watch":                   resourceXrayWatch(),
+		"xray_ignore_rule":             resourceXrayIgnoreRule(),
+	} {
+		if !resource.Schema["project_key"].ForceNew {
+			t.Errorf("%s: expected 'project_key' to force a new resource", name)
+		}
+	}
+}
+
+func TestComputedSchema(t *testing.T) {
+	computed := computedSchema(map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"block": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"flag": {
+						Type:     schema.TypeBool,
+						Optional: true,
+						Default:  true,
+					},
+				},
+			},
+		},
+	})
+
+	if err := schema.InternalMap(computed).InternalValidate(nil); err != nil {
+		t.Fatalf("expected a valid data source schema, got: %s", err)
+	}
+
+	flag := computed["block"].Elem.(*schema.Resource).Schema["flag"]
+	if !computed["name"].Computed || computed["name"].Required || !flag.Computed || flag.Default != nil {
+		t.Error("expected all attributes to be computed only")
+	}
+}
+
+// testXrayServer serves the policies and watches from memory, and the other requests from the handlers
+// of routes. The requests matching a key of failures fail with an internal server error. Routes and
+// failures are keyed by "<method> <path>", and the requests are recorded in the same format.
+type testXrayServer struct {
+	lock     sync.Mutex
+	policies map[string]json.RawMessage
+	watches  map[string]json.RawMessage
+	routes   map[string]http.HandlerFunc
+	failures map[string]bool
+	requests []string
+}
+
+func (s *testXrayServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	route := r.Method + " " + r.URL.Path
+	s.requests = append(s.requests, route)
+	w.Header().Set("Content-Type", "application/json")
+	if s.failures[route] {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if handler, ok := s.routes[route]; ok {
+		handler(w, r)
+		return
+	}
+
+	var objects map[string]json.RawMessage
+	var name string
+	switch {
+	case r.URL.Path == "/xray/api/v2/policies" || strings.HasPrefix(r.URL.Path, "/xray/api/v2/policies/"):
+		objects = s.policies
+		name = strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/xray/api/v2/policies"), "/")
+	case r.URL.Path == "/xray/api/v2/watches" || strings.HasPrefix(r.URL.Path, "/xray/api/v2/watches/"):
+		objects = s.watches
+		name = strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/xray/api/v2/watches"), "/")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if len(name) == 0 {
+			var names []string
+			for name := range objects {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			var list []json.RawMessage
+			for _, name := range names {
+				list = append(list, objects[name])
+			}
+			_ = json.NewEncoder(w).Encode(list)
+			return
+		}
+		body, ok := objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	case http.MethodPost, http.MethodPut:
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodPost {
+			var named struct {
+				Name        string `json:"name"`
+				GeneralData struct {
+					Name string `json:"name"`
+				} `json:"general_data"`
+			}
+			_ = json.Unmarshal(body, &named)
+			name = named.Name + named.GeneralData.Name
+			if _, ok := objects[name]; ok {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		} else if _, ok := objects[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		objects[name] = body
+	case http.MethodDelete:
+		if _, ok := objects[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(objects, name)
+	}
+}
+
+func newTestXrayServer(t *testing.T) (*testXrayServer, *resty.Client, func()) {
+	xray := &testXrayServer{
+		policies: map[string]json.RawMessage{},
+		watches:  map[string]json.RawMessage{},
+		routes:   map[string]http.HandlerFunc{},
+		failures: map[string]bool{},
+	}
+	server := httptest.NewServer(xray)
+
+	restyClient, err := client.Build(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return xray, restyClient, server.Close
+}
+
+// jsonResponse is a route of testXrayServer answering with the status and the JSON body
+func jsonResponse(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+// addConfigurationRoutes adds the routes reading and updating a configuration of Xray, kept in configuration
+func (s *testXrayServer) addConfigurationRoutes(t *testing.T, path string, configuration *json.RawMessage) {
+	s.routes[http.MethodGet+" "+path] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(*configuration)
+	}
+	s.routes[http.MethodPut+" "+path] = func(_ http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(configuration); err != nil {
+			t.Error(err)
+		}
+	}
+}
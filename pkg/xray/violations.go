@@ -0,0 +1,146 @@
+package xray
+
+import (
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// violationSeverities are the severities of the violation counts, from the highest
+var violationSeverities = []string{"Critical", "High", "Medium", "Low"}
+
+type ViolationsFilters struct {
+	WatchName   string `json:"watch_name,omitempty"`
+	PolicyName  string `json:"policy_name,omitempty"`
+	MinSeverity string `json:"min_severity,omitempty"`
+}
+
+type ViolationsPagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type ViolationsRequest struct {
+	Filters    ViolationsFilters    `json:"filters"`
+	Pagination ViolationsPagination `json:"pagination"`
+}
+
+type ViolationsResponse struct {
+	TotalViolations int `json:"total_violations"`
+}
+
+var violationCountsSchema = map[string]*schema.Schema{
+	"include_violation_counts": {
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Default value is `false`. Read the counts of the violations from the Xray violations API on every refresh, into `violation_counts`. It adds four calls to the violations API to each refresh, one per severity. A failure to read the counts is reported as a warning.",
+	},
+	"violation_counts": {
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The counts of the violations, when `include_violation_counts` is set.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"total": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Number of violations, of the severities from `Low` to `Critical`.",
+				},
+				"critical": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Number of violations with the `Critical` severity.",
+				},
+				"high": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Number of violations with the `High` severity.",
+				},
+				"medium": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Number of violations with the `Medium` severity.",
+				},
+				"low": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "Number of violations with the `Low` severity.",
+				},
+			},
+		},
+	},
+}
+
+// countViolations returns the number of violations matching the filters. Only the total is read,
+// the violations themselves are not returned.
+func countViolations(client *resty.Client, projectKey string, filters ViolationsFilters) (int, error) {
+	violations := ViolationsResponse{}
+
+	req, err := getRestyRequest(client, projectKey)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = req.
+		SetBody(ViolationsRequest{
+			Filters: filters,
+			// Xray pages start at 1
+			Pagination: ViolationsPagination{Limit: 1, Offset: 1},
+		}).
+		SetResult(&violations).
+		Post("xray/api/v1/violations")
+	if err != nil {
+		return 0, err
+	}
+
+	return violations.TotalViolations, nil
+}
+
+// getViolationCounts returns the per-severity counts of the violations matching the filters, and their total.
+// The API only filters by minimal severity, so the count of a severity is the count from this severity minus
+// the count from the next higher one, and the total is the count from the lowest severity.
+func getViolationCounts(client *resty.Client, projectKey string, filters ViolationsFilters) (map[string]interface{}, error) {
+	counts := map[string]interface{}{}
+
+	higher := 0
+	for _, severity := range violationSeverities {
+		filters.MinSeverity = severity
+		count, err := countViolations(client, projectKey, filters)
+		if err != nil {
+			return nil, err
+		}
+		counts[strings.ToLower(severity)] = count - higher
+		higher = count
+	}
+	counts["total"] = higher
+
+	return counts, nil
+}
+
+// packViolationCounts sets violation_counts from the violations matching the filters, or clears it
+// when include_violation_counts is not set. The counts are informative, so a failure to read them, for
+// example without the permission on the violations API, is a warning and leaves violation_counts as is.
+func packViolationCounts(d *schema.ResourceData, client *resty.Client, filters ViolationsFilters) diag.Diagnostics {
+	if !d.Get("include_violation_counts").(bool) {
+		if err := d.Set("violation_counts", nil); err != nil {
+			return diag.FromErr(err)
+		}
+		return nil
+	}
+
+	counts, err := getViolationCounts(client, d.Get("project_key").(string), filters)
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Failed to read the violation counts",
+			Detail:   err.Error(),
+		}}
+	}
+	if err := d.Set("violation_counts", []interface{}{counts}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
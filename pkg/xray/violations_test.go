@@ -0,0 +1,142 @@
+package xray
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jfrog/terraform-provider-shared/test"
+)
+
+// testViolationsRoute counts the violations of the watch by minimal severity
+func testViolationsRoute(t *testing.T, watchName string, countsBySeverity map[string]int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request ViolationsRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		if request.Filters.WatchName != watchName {
+			t.Errorf("unexpected watch name filter: %s", request.Filters.WatchName)
+		}
+
+		total := 0
+		for _, severity := range violationSeverities {
+			total += countsBySeverity[severity]
+			if severity == request.Filters.MinSeverity {
+				break
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(ViolationsResponse{TotalViolations: total}); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestPackViolationCounts(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.routes["POST /xray/api/v1/violations"] = testViolationsRoute(t, "test-watch", map[string]int{"Critical": 1, "High": 2, "Low": 4})
+
+	d := schema.TestResourceDataRaw(t, resourceXrayWatch().Schema, map[string]interface{}{
+		"name":                     "test-watch",
+		"include_violation_counts": true,
+	})
+	if diags := packViolationCounts(d, restyClient, ViolationsFilters{WatchName: "test-watch"}); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := map[string]int{"total": 7, "critical": 1, "high": 2, "medium": 0, "low": 4}
+	for key, count := range expected {
+		if actual := d.Get("violation_counts.0." + key).(int); actual != count {
+			t.Errorf("expected %s count %d, got %d", key, count, actual)
+		}
+	}
+	if len(xray.requests) != 4 {
+		t.Errorf("expected 4 calls to the violations API, got %v", xray.requests)
+	}
+}
+
+func TestPackViolationCountsFailure(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.failures["POST /xray/api/v1/violations"] = true
+
+	d := schema.TestResourceDataRaw(t, resourceXrayWatch().Schema, map[string]interface{}{
+		"name":                     "test-watch",
+		"include_violation_counts": true,
+	})
+	diags := packViolationCounts(d, restyClient, ViolationsFilters{WatchName: "test-watch"})
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a warning, got %v", diags)
+	}
+	if count := d.Get("violation_counts.#").(int); count != 0 {
+		t.Errorf("expected no violation counts, got %d", count)
+	}
+}
+
+func TestPackViolationCountsDisabled(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	xray.routes["POST /xray/api/v1/violations"] = testViolationsRoute(t, "test-watch", map[string]int{"Critical": 1})
+
+	d := schema.TestResourceDataRaw(t, resourceXrayWatch().Schema, map[string]interface{}{
+		"name": "test-watch",
+	})
+	if diags := packViolationCounts(d, restyClient, ViolationsFilters{WatchName: "test-watch"}); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if count := d.Get("violation_counts.#").(int); count != 0 {
+		t.Errorf("expected no violation counts, got %d", count)
+	}
+	if len(xray.requests) != 0 {
+		t.Errorf("expected no call to the violations API, got %v", xray.requests)
+	}
+}
+
+func TestAccSecurityPolicy_violationCounts(t *testing.T) {
+	_, fqrn, resourceName := test.MkNames("policy-", "xray_security_policy")
+	policyName := fmt.Sprintf("terraform-security-policy-%d", test.RandomInt())
+
+	config := fmt.Sprintf(`
+		resource "xray_security_policy" "%[1]s" {
+			name                     = "%[2]s"
+			type                     = "security"
+			include_violation_counts = true
+
+			rule {
+				name     = "rule-name-severity"
+				priority = 1
+				criteria {
+					min_severity = "High"
+				}
+				actions {
+					block_download {
+						unscanned = false
+						active    = false
+					}
+				}
+			}
+		}
+	`, resourceName, policyName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		CheckDestroy:      verifyDeleted(fqrn, testCheckPolicy),
+		ProviderFactories: testAccProviders(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(fqrn, "violation_counts.#", "1"),
+					resource.TestCheckResourceAttr(fqrn, "violation_counts.0.total", "0"),
+				),
+			},
+		},
+	})
+}
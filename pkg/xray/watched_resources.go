@@ -0,0 +1,189 @@
+package xray
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/exp/slices"
+)
+
+var checkWatchedResourcesModes = []string{"warning", "error"}
+
+// ArtifactoryRepository is the part of the configuration of an Artifactory repository checked for the watches
+type ArtifactoryRepository struct {
+	Key        string `json:"key"`
+	Rclass     string `json:"rclass"`
+	ProjectKey string `json:"projectKey"`
+	XrayIndex  bool   `json:"xrayIndex"`
+}
+
+type IndexedBuilds struct {
+	IndexedBuilds []string `json:"indexed_builds"`
+}
+
+// checkWatchedResources checks the repositories, builds and projects of the watch resources in Artifactory:
+// they must exist, be assigned to the project of the watch, and the repositories and builds must be indexed
+// by Xray. The watch resources are read from the raw configuration, so the names which aren't known yet,
+// such as the keys of the repositories created by the same apply, are skipped. The errors of the requests
+// other than a missing resource skip the check of the resource as well, as it's only meant to catch the
+// mistakes before the watch silently watches nothing.
+func checkWatchedResources(ctx context.Context, client *resty.Client, projectKey string, watchResources []interface{}) []string {
+	var errs []string
+	for _, raw := range watchResources {
+		watchResource, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := watchResource["name"].(string)
+		if len(name) == 0 {
+			continue
+		}
+
+		var resourceErrs []string
+		var err error
+		resourceType, _ := watchResource["type"].(string)
+		switch canonicalEnumValue(resourceType, watchResourceTypes) {
+		case "repository":
+			repoType, _ := watchResource["repo_type"].(string)
+			resourceErrs, err = checkWatchedRepository(client, projectKey, name, canonicalEnumValue(repoType, watchRepoTypes))
+		case "build":
+			// The raw configuration doesn't have the default of bin_mgr_id
+			binMgrId, _ := watchResource["bin_mgr_id"].(string)
+			if len(binMgrId) == 0 {
+				binMgrId = "default"
+			}
+			resourceErrs, err = checkWatchedBuild(client, projectKey, name, binMgrId)
+		case "project":
+			resourceErrs, err = checkWatchedProject(client, name)
+		}
+		if err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Skipping the check of the watched resource %s: %s", name, err))
+			continue
+		}
+		// watch_resource is a set, so the errors name the resources by their type and name rather than by their index
+		for _, resourceErr := range resourceErrs {
+			errs = append(errs, fmt.Sprintf("watch_resource: %s", resourceErr))
+		}
+	}
+
+	return errs
+}
+
+// configuredWatchResources returns the watch resources of the raw configuration
+func configuredWatchResources(rawConfig cty.Value) []interface{} {
+	config, _ := rawConfigValue(rawConfig).(map[string]interface{})
+	watchResources, _ := config["watch_resource"].([]interface{})
+	return watchResources
+}
+
+func checkWatchedRepository(client *resty.Client, projectKey, name, repoType string) ([]string, error) {
+	repository := ArtifactoryRepository{}
+	resp, err := client.R().
+		SetResult(&repository).
+		SetPathParams(map[string]string{
+			"key": name,
+		}).
+		Get("artifactory/api/repositories/{key}")
+	if err != nil {
+		// Artifactory answers 400 rather than 404 for a missing repository
+		if resp != nil && (resp.StatusCode() == http.StatusNotFound || resp.StatusCode() == http.StatusBadRequest) {
+			return []string{fmt.Sprintf("repository '%s' doesn't exist", name)}, nil
+		}
+		return nil, err
+	}
+
+	var errs []string
+	// A federated repository is a local repository replicated to other Artifactory instances
+	if len(repoType) > 0 && repository.Rclass != repoType && !(repoType == "local" && repository.Rclass == "federated") {
+		errs = append(errs, fmt.Sprintf("repository '%s' is a %s repository, but 'repo_type' is '%s'", name, repository.Rclass, repoType))
+	}
+	if len(projectKey) > 0 && repository.ProjectKey != projectKey {
+		errs = append(errs, fmt.Sprintf("repository '%s' isn't assigned to project '%s'", name, projectKey))
+	}
+	if !repository.XrayIndex {
+		errs = append(errs, fmt.Sprintf("Xray indexing isn't enabled on repository '%s'", name))
+	}
+
+	return errs, nil
+}
+
+func checkWatchedBuild(client *resty.Client, projectKey, name, binMgrId string) ([]string, error) {
+	req := client.R()
+	if len(projectKey) > 0 {
+		// The builds of a project are in the build info repository of the project
+		req = req.SetQueryParam("project", projectKey)
+	}
+	resp, err := req.
+		SetPathParams(map[string]string{
+			"name": name,
+		}).
+		Get("artifactory/api/build/{name}")
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			return []string{fmt.Sprintf("build '%s' doesn't exist%s", name, inProject(projectKey))}, nil
+		}
+		return nil, err
+	}
+
+	indexedBuilds := IndexedBuilds{}
+	req, err = getRestyRequest(client, projectKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = req.
+		SetResult(&indexedBuilds).
+		SetPathParams(map[string]string{
+			"binMgrId": binMgrId,
+		}).
+		Get("xray/api/v1/binMgr/{binMgrId}/builds")
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(indexedBuilds.IndexedBuilds, name) {
+		return []string{fmt.Sprintf("Xray indexing isn't enabled on build '%s'", name)}, nil
+	}
+
+	return nil, nil
+}
+
+func checkWatchedProject(client *resty.Client, name string) ([]string, error) {
+	resp, err := client.R().
+		SetPathParams(map[string]string{
+			"key": name,
+		}).
+		Get("access/api/v1/projects/{key}")
+	if err != nil {
+		if resp != nil && resp.StatusCode() == http.StatusNotFound {
+			return []string{fmt.Sprintf("project '%s' doesn't exist", name)}, nil
+		}
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// watchedResourcesWarnings returns the problems of the watched resources as warnings, when
+// check_watched_resources is `warning`. The CustomizeDiff functions can only return errors, so the
+// warnings are returned when the watch is created or updated.
+func watchedResourcesWarnings(ctx context.Context, d *schema.ResourceData, client *resty.Client) diag.Diagnostics {
+	if canonicalEnumValue(d.Get("check_watched_resources").(string), checkWatchedResourcesModes) != "warning" {
+		return nil
+	}
+
+	var diags diag.Diagnostics
+	for _, err := range checkWatchedResources(ctx, client, d.Get("project_key").(string), configuredWatchResources(d.GetRawConfig())) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Invalid watched resource",
+			Detail:   err,
+		})
+	}
+
+	return diags
+}
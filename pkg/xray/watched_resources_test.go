@@ -0,0 +1,56 @@
+package xray
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCheckWatchedResources(t *testing.T) {
+	xray, restyClient, closeServer := newTestXrayServer(t)
+	defer closeServer()
+	for path, body := range map[string]string{
+		"/artifactory/api/repositories/libs-release-local": `{"key":"libs-release-local","rclass":"local","projectKey":"myproj","xrayIndex":true}`,
+		"/artifactory/api/repositories/libs-federated":     `{"key":"libs-federated","rclass":"federated","projectKey":"myproj","xrayIndex":true}`,
+		"/artifactory/api/repositories/maven-remote":       `{"key":"maven-remote","rclass":"remote","projectKey":"","xrayIndex":false}`,
+		"/artifactory/api/build/indexed-build":             `{"buildsNumbers":[{"uri":"/1"}]}`,
+		"/artifactory/api/build/other-build":               `{"buildsNumbers":[{"uri":"/1"}]}`,
+		"/xray/api/v1/binMgr/default/builds":               `{"indexed_builds":["indexed-build"],"non_indexed_builds":["other-build"]}`,
+		"/access/api/v1/projects/myproj":                   `{"project_key":"myproj"}`,
+	} {
+		xray.routes[http.MethodGet+" "+path] = jsonResponse(http.StatusOK, body)
+	}
+
+	errs := checkWatchedResources(context.Background(), restyClient, "myproj", []interface{}{
+		map[string]interface{}{"type": "repository", "name": "libs-release-local", "repo_type": "local"},
+		map[string]interface{}{"type": "repository", "name": "libs-federated", "repo_type": "local"},
+		map[string]interface{}{"type": "repository", "name": "maven-remote", "repo_type": "local"},
+		map[string]interface{}{"type": "repository", "name": "libs-snapshot-local", "repo_type": "local"},
+		map[string]interface{}{"type": "repository", "name": nil, "repo_type": "local"},
+		map[string]interface{}{"type": "build", "name": "indexed-build", "bin_mgr_id": "default"},
+		map[string]interface{}{"type": "build", "name": "other-build", "bin_mgr_id": "default"},
+		map[string]interface{}{"type": "build", "name": "missing-build", "bin_mgr_id": "default"},
+		map[string]interface{}{"type": "build", "name": "other-build"},
+		map[string]interface{}{"type": "project", "name": "myproj"},
+		map[string]interface{}{"type": "Project", "name": "otherproj"},
+		map[string]interface{}{"type": "all-repos"},
+	})
+
+	expected := []string{
+		"watch_resource: repository 'maven-remote' is a remote repository, but 'repo_type' is 'local'",
+		"watch_resource: repository 'maven-remote' isn't assigned to project 'myproj'",
+		"watch_resource: Xray indexing isn't enabled on repository 'maven-remote'",
+		"watch_resource: repository 'libs-snapshot-local' doesn't exist",
+		"watch_resource: Xray indexing isn't enabled on build 'other-build'",
+		"watch_resource: build 'missing-build' doesn't exist in project 'myproj'",
+		"watch_resource: Xray indexing isn't enabled on build 'other-build'",
+		"watch_resource: project 'otherproj' doesn't exist",
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %q, got %q", expected, errs)
+	}
+	if len(xray.requests) != 13 {
+		t.Errorf("expected the unknown name to be skipped, got the requests %q", xray.requests)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -57,6 +58,12 @@ type Watch struct {
 	WatchRecipients  []string              `json:"watch_recipients"`
 }
 
+// Canonical values of the watch enums, as returned by Xray
+var watchResourceTypes = []string{"all-repos", "repository", "all-builds", "build", "project", "all-projects"}
+var watchRepoTypes = []string{"local", "remote"}
+var watchFilterTypes = []string{"regex", "package-type"}
+var assignedPolicyTypes = []string{"security", "license", "operational_risk", "exposures"}
+
 func unpackWatch(d *schema.ResourceData) Watch {
 	watch := Watch{}
 
@@ -110,7 +117,7 @@ func unpackProjectResource(rawCfg interface{}) WatchProjectResource {
 	resource := WatchProjectResource{}
 
 	cfg := rawCfg.(map[string]interface{})
-	resource.Type = cfg["type"].(string)
+	resource.Type = canonicalEnumValue(cfg["type"].(string), watchResourceTypes)
 
 	if v, ok := cfg["bin_mgr_id"]; ok {
 		resource.BinaryManagerId = v.(string)
@@ -121,7 +128,7 @@ func unpackProjectResource(rawCfg interface{}) WatchProjectResource {
 	}
 
 	if v, ok := cfg["repo_type"]; ok {
-		resource.RepoType = v.(string)
+		resource.RepoType = canonicalEnumValue(v.(string), watchRepoTypes)
 	}
 
 	if v, ok := cfg["filter"]; ok {
@@ -145,7 +152,7 @@ func unpackFilters(d *schema.Set) []WatchFilter {
 	for _, raw := range tfFilters {
 		f := raw.(map[string]interface{})
 		filter := WatchFilter{
-			Type:  f["type"].(string),
+			Type:  canonicalEnumValue(f["type"].(string), watchFilterTypes),
 			Value: json.RawMessage(strconv.Quote(f["value"].(string))),
 		}
 		filters = append(filters, filter)
@@ -187,7 +194,7 @@ func unpackAssignedPolicy(rawCfg interface{}) WatchAssignedPolicy {
 
 	cfg := rawCfg.(map[string]interface{})
 	policy.Name = cfg["name"].(string)
-	policy.Type = cfg["type"].(string)
+	policy.Type = canonicalEnumValue(cfg["type"].(string), assignedPolicyTypes)
 
 	return policy
 }
@@ -198,20 +205,21 @@ func packProjectResources(ctx context.Context, resources WatchProjectResources)
 	var resourceMaps []interface{}
 
 	for _, res := range resources.Resources {
+		resourceType := canonicalEnumValue(res.Type, watchResourceTypes)
 		resourceMap := map[string]interface{}{}
-		resourceMap["type"] = res.Type
+		resourceMap["type"] = resourceType
 		// only pack watch resource name if type isn't for all-*
 		// Xray API returns a generated name for all-* type which will
 		// cause TF to want to update the resource since it doesn't match
 		// the configuration.
-		if len(res.Name) > 0 && !slices.Contains(allTypes, res.Type) {
+		if len(res.Name) > 0 && !slices.Contains(allTypes, resourceType) {
 			resourceMap["name"] = res.Name
 		}
 		if len(res.BinaryManagerId) > 0 {
 			resourceMap["bin_mgr_id"] = res.BinaryManagerId
 		}
 		if len(res.RepoType) > 0 {
-			resourceMap["repo_type"] = res.RepoType
+			resourceMap["repo_type"] = canonicalEnumValue(res.RepoType, watchRepoTypes)
 		}
 
 		resourceMap, errors := packFilters(res.Filters, resourceMap)
@@ -235,7 +243,7 @@ func packStringFilter(filter WatchFilter) (map[string]interface{}, error) {
 	}
 
 	return map[string]interface{}{
-		"type":  filter.Type,
+		"type":  canonicalEnumValue(filter.Type, watchFilterTypes),
 		"value": value,
 	}, nil
 }
@@ -293,7 +301,7 @@ func packAssignedPolicies(policies []WatchAssignedPolicy) []interface{} {
 	for _, p := range policies {
 		assignedPolicy := map[string]interface{}{
 			"name": p.Name,
-			"type": p.Type,
+			"type": canonicalEnumValue(p.Type, assignedPolicyTypes),
 		}
 		assignedPolicies = append(assignedPolicies, assignedPolicy)
 	}
@@ -318,6 +326,19 @@ func packWatch(ctx context.Context, watch Watch, d *schema.ResourceData) diag.Di
 	return nil
 }
 
+// addWatchBuildRepo adds 'build_repo' to the build resources if project_key is specified.
+// undocumented Xray API structure that is required!
+func addWatchBuildRepo(watch *Watch) {
+	if len(watch.ProjectKey) == 0 {
+		return
+	}
+	for idx, resource := range watch.ProjectResources.Resources {
+		if resource.Type == "build" {
+			watch.ProjectResources.Resources[idx].BuildRepo = fmt.Sprintf("%s-build-info", watch.ProjectKey)
+		}
+	}
+}
+
 func resourceXrayWatchCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	watch := unpackWatch(d)
 
@@ -326,25 +347,41 @@ func resourceXrayWatchCreate(ctx context.Context, d *schema.ResourceData, m inte
 		return diag.FromErr(err)
 	}
 
-	// add 'build_repo' to resource if project_key is specified.
-	// undocumented Xray API structure that is required!
-	if len(watch.ProjectKey) > 0 {
-		for idx, resource := range watch.ProjectResources.Resources {
-			if resource.Type == "build" {
-				watch.ProjectResources.Resources[idx].BuildRepo = fmt.Sprintf("%s-build-info", watch.ProjectKey)
-			}
+	if d.Get("adopt_existing").(bool) {
+		exists, err := watchExists(m.(*resty.Client), watch.ProjectKey, watch.GeneralData.Name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if exists {
+			tflog.Info(ctx, fmt.Sprintf("Adopting the existing Xray watch (%s)", watch.GeneralData.Name))
+			d.SetId(watch.GeneralData.Name)
+			return resourceXrayWatchUpdate(ctx, d, m)
 		}
 	}
 
+	addWatchBuildRepo(&watch)
+
 	_, err = req.
 		SetBody(watch).
 		Post("xray/api/v2/watches")
 	if err != nil {
+		if exists, existsErr := watchExists(m.(*resty.Client), watch.ProjectKey, watch.GeneralData.Name); existsErr == nil && exists {
+			return alreadyExistsDiagnostics("xray_watch", fmt.Sprintf("Watch %s", watch.GeneralData.Name), watch.GeneralData.Name)
+		}
 		return diag.FromErr(err)
 	}
 
 	d.SetId(watch.GeneralData.Name)
-	return resourceXrayWatchRead(ctx, d, m)
+	diags := resourceXrayWatchRead(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, watchedResourcesWarnings(ctx, d, m.(*resty.Client))...)
+}
+
+func watchExists(client *resty.Client, projectKey, name string) (bool, error) {
+	return objectExists(client, projectKey, "xray/api/v2/watches/{name}", map[string]string{"name": name})
 }
 
 func resourceXrayWatchRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -370,7 +407,12 @@ func resourceXrayWatchRead(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.FromErr(err)
 	}
 
-	return packWatch(ctx, watch, d)
+	diags := packWatch(ctx, watch, d)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, packViolationCounts(d, m.(*resty.Client), ViolationsFilters{WatchName: d.Id()})...)
 }
 
 func resourceXrayWatchUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -396,7 +438,12 @@ func resourceXrayWatchUpdate(ctx context.Context, d *schema.ResourceData, m inte
 	}
 
 	d.SetId(watch.GeneralData.Name)
-	return resourceXrayWatchRead(ctx, d, m)
+	diags := resourceXrayWatchRead(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, watchedResourcesWarnings(ctx, d, m.(*resty.Client))...)
 }
 
 func resourceXrayWatchDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -419,14 +466,61 @@ func resourceXrayWatchDelete(_ context.Context, d *schema.ResourceData, m interf
 	return nil
 }
 
-func watchResourceDiff(_ context.Context, diff *schema.ResourceDiff, v interface{}) error {
+// assignedPolicyReferences returns the references to the assigned policies, read from the raw configuration.
+// The unknown values are skipped.
+func assignedPolicyReferences(diff *schema.ResourceDiff) []objectReference {
+	rawConfig := diff.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil
+	}
+	config := rawConfigValue(rawConfig).(map[string]interface{})
+
+	var references []objectReference
+	assignedPolicies, _ := config["assigned_policy"].([]interface{})
+	for idx, raw := range assignedPolicies {
+		policy, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := policy["name"].(string)
+		if !ok {
+			continue
+		}
+		policyType, _ := policy["type"].(string)
+		references = append(references, objectReference{
+			Path: fmt.Sprintf("assigned_policy.%d.name", idx),
+			Name: name,
+			Type: canonicalEnumValue(policyType, assignedPolicyTypes),
+		})
+	}
+
+	return references
+}
+
+func watchResourceDiff(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	projectKey := diff.Get("project_key").(string)
+	recordPlannedObject("watch", projectKey, diff.Get("name").(string), "")
+
+	if client, ok := v.(*resty.Client); ok && client != nil && referencesMayHaveChanged(diff, "assigned_policy") {
+		if errs := checkPolicyReferences(ctx, client, projectKey, assignedPolicyReferences(diff)); len(errs) > 0 {
+			return fmt.Errorf("invalid references:\n%s", strings.Join(errs, "\n"))
+		}
+	}
+
 	watchResources := diff.Get("watch_resource").(*schema.Set).List()
 	if len(watchResources) == 0 {
 		return nil
 	}
+
+	if client, ok := v.(*resty.Client); ok && client != nil && canonicalEnumValue(diff.Get("check_watched_resources").(string), checkWatchedResourcesModes) == "error" &&
+		diff.NewValueKnown("project_key") && referencesMayHaveChanged(diff, "watch_resource", "check_watched_resources") {
+		if errs := checkWatchedResources(ctx, client, projectKey, configuredWatchResources(diff.GetRawConfig())); len(errs) > 0 {
+			return fmt.Errorf("invalid watched resources:\n%s", strings.Join(errs, "\n"))
+		}
+	}
 	for _, watchResource := range watchResources {
 		r := watchResource.(map[string]interface{})
-		resourceType := r["type"].(string)
+		resourceType := canonicalEnumValue(r["type"].(string), watchResourceTypes)
 
 		// validate repo_type
 		repoType := r["repo_type"].(string)